@@ -0,0 +1,39 @@
+package benchmark
+
+import (
+	"net"
+	"time"
+)
+
+// CPSResult is the outcome of a connections-per-second measurement: one
+// latency sample per successful fresh TCP connection, and a count of
+// connections that failed to establish.
+type CPSResult struct {
+	Stats  Stats
+	Errors int
+}
+
+// BenchmarkCPS opens a brand new TCP connection to addr, measures how long
+// the handshake takes, then closes it, repeating for duration. This
+// exercises accept-path cost independent of request/response handling,
+// unlike BenchmarkRPS, which times full request/response round trips.
+func BenchmarkCPS(addr string, duration time.Duration) CPSResult {
+	deadline := time.Now().Add(duration)
+	var samples []time.Duration
+	var errs int
+
+	dialer := net.Dialer{Timeout: 2 * time.Second}
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		conn, err := dialer.Dial("tcp", addr)
+		elapsed := time.Since(start)
+		if err != nil {
+			errs++
+			continue
+		}
+		conn.Close()
+		samples = append(samples, elapsed)
+	}
+
+	return CPSResult{Stats: Aggregate(samples), Errors: errs}
+}
@@ -0,0 +1,76 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// EndpointResult pairs webserv's and nginx's Stats for the same endpoint,
+// so a report can render them side-by-side.
+type EndpointResult struct {
+	Endpoint string `json:"endpoint"`
+	Webserv  Stats  `json:"webserv"`
+	Nginx    Stats  `json:"nginx"`
+}
+
+// Report is the full comparison suite: one EndpointResult per exercised
+// endpoint.
+type Report struct {
+	Results []EndpointResult `json:"results"`
+}
+
+// WriteJSON writes the report as indented JSON to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteJSONFile writes the report as indented JSON to the file at path.
+func (r *Report) WriteJSONFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.WriteJSON(f)
+}
+
+// WriteMarkdown renders the report as a Markdown table comparing webserv
+// and nginx latency percentiles for each endpoint.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "| Endpoint | Server | N | Min | Median | Mean | P95 | P99 | Max | StdDev |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		if err := writeMarkdownRow(w, res.Endpoint, "webserv", res.Webserv); err != nil {
+			return err
+		}
+		if err := writeMarkdownRow(w, res.Endpoint, "nginx", res.Nginx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMarkdownFile renders the report as a Markdown table to the file at
+// path.
+func (r *Report) WriteMarkdownFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.WriteMarkdown(f)
+}
+
+func writeMarkdownRow(w io.Writer, endpoint, server string, s Stats) error {
+	_, err := fmt.Fprintf(w, "| %s | %s | %d | %v | %v | %v | %v | %v | %v | %v |\n",
+		endpoint, server, s.N, s.Min, s.Median, s.Mean, s.P95, s.P99, s.Max, s.StdDev)
+	return err
+}
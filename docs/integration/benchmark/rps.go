@@ -0,0 +1,47 @@
+package benchmark
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// RPSResult is the outcome of BenchmarkRPS: Stats aggregates one latency
+// sample per completed round trip. Requests that error are counted as
+// failures and excluded from the latency samples; Errors reports how many
+// occurred.
+type RPSResult struct {
+	Stats  Stats
+	Errors int
+}
+
+// BenchmarkRPS drives requests-per-second against a single endpoint for
+// duration using client, building a fresh *http.Request from newRequest
+// each iteration (so POST bodies can be re-materialized per attempt).
+func BenchmarkRPS(client *http.Client, newRequest func() (*http.Request, error), duration time.Duration) RPSResult {
+	deadline := time.Now().Add(duration)
+	var samples []time.Duration
+	var errs int
+
+	for time.Now().Before(deadline) {
+		req, err := newRequest()
+		if err != nil {
+			errs++
+			continue
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		elapsed := time.Since(start)
+		if err != nil {
+			errs++
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		samples = append(samples, elapsed)
+	}
+
+	return RPSResult{Stats: Aggregate(samples), Errors: errs}
+}
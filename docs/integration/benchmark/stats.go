@@ -0,0 +1,74 @@
+// Package benchmark turns ad hoc b.N request loops into a structured
+// throughput/latency suite: fixed-duration sampling with gmeasure-style
+// aggregation (min/median/p95/p99/max, stddev), plus JSON and Markdown
+// reports comparing two servers side-by-side, endpoint by endpoint.
+package benchmark
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Stats summarizes a set of latency samples the way gmeasure's
+// experiment/measurement aggregation does.
+type Stats struct {
+	N      int           `json:"n"`
+	Min    time.Duration `json:"minNs"`
+	Median time.Duration `json:"medianNs"`
+	Mean   time.Duration `json:"meanNs"`
+	P95    time.Duration `json:"p95Ns"`
+	P99    time.Duration `json:"p99Ns"`
+	Max    time.Duration `json:"maxNs"`
+	StdDev time.Duration `json:"stddevNs"`
+}
+
+// Aggregate computes Stats over samples. It does not mutate samples.
+func Aggregate(samples []time.Duration) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, s := range sorted {
+		sum += s
+	}
+	mean := sum / time.Duration(len(sorted))
+
+	var variance float64
+	for _, s := range sorted {
+		d := float64(s - mean)
+		variance += d * d
+	}
+	variance /= float64(len(sorted))
+
+	return Stats{
+		N:      len(sorted),
+		Min:    sorted[0],
+		Median: percentile(sorted, 0.50),
+		Mean:   mean,
+		P95:    percentile(sorted, 0.95),
+		P99:    percentile(sorted, 0.99),
+		Max:    sorted[len(sorted)-1],
+		StdDev: time.Duration(math.Sqrt(variance)),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of a sorted slice.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
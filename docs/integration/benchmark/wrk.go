@@ -0,0 +1,104 @@
+package benchmark
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ErrNoExternalBenchmarkTool is returned by RunWrk when neither wrk nor hey
+// is installed, so callers can skip rather than fail.
+var ErrNoExternalBenchmarkTool = errors.New("benchmark: neither wrk nor hey found on PATH")
+
+// WrkResult is the subset of wrk/hey output this package parses.
+type WrkResult struct {
+	Tool           string // "wrk" or "hey"
+	RequestsPerSec float64
+	AvgLatency     time.Duration
+	RawOutput      string
+}
+
+var (
+	wrkRPSRe     = regexp.MustCompile(`Requests/sec:\s*([0-9.]+)`)
+	wrkLatencyRe = regexp.MustCompile(`Latency\s+([0-9.]+)(us|ms|s)`)
+	heyRPSRe     = regexp.MustCompile(`Requests/sec:\s*([0-9.]+)`)
+	heyLatencyRe = regexp.MustCompile(`Average:\s*([0-9.]+)\s*secs`)
+)
+
+// RunWrk shells out to wrk if it's on PATH, falling back to hey, running a
+// short load test against url. It returns ErrNoExternalBenchmarkTool if
+// neither is installed - callers should skip, not fail, on that error.
+func RunWrk(url string, duration time.Duration, connections int) (*WrkResult, error) {
+	if path, err := exec.LookPath("wrk"); err == nil {
+		return runWrk(path, url, duration, connections)
+	}
+	if path, err := exec.LookPath("hey"); err == nil {
+		return runHey(path, url, duration, connections)
+	}
+	return nil, ErrNoExternalBenchmarkTool
+}
+
+func runWrk(path, url string, duration time.Duration, connections int) (*WrkResult, error) {
+	cmd := exec.Command(path, "-d", duration.String(), "-c", strconv.Itoa(connections), url)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errFromOutput("wrk", err, output)
+	}
+
+	result := &WrkResult{Tool: "wrk", RawOutput: string(output)}
+	if m := wrkRPSRe.FindSubmatch(output); m != nil {
+		result.RequestsPerSec, _ = strconv.ParseFloat(string(m[1]), 64)
+	}
+	if m := wrkLatencyRe.FindSubmatch(output); m != nil {
+		value, _ := strconv.ParseFloat(string(m[1]), 64)
+		result.AvgLatency = parseWrkUnit(value, string(m[2]))
+	}
+	return result, nil
+}
+
+func runHey(path, url string, duration time.Duration, connections int) (*WrkResult, error) {
+	cmd := exec.Command(path, "-z", duration.String(), "-c", strconv.Itoa(connections), url)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errFromOutput("hey", err, output)
+	}
+
+	result := &WrkResult{Tool: "hey", RawOutput: string(output)}
+	if m := heyRPSRe.FindSubmatch(output); m != nil {
+		result.RequestsPerSec, _ = strconv.ParseFloat(string(m[1]), 64)
+	}
+	if m := heyLatencyRe.FindSubmatch(output); m != nil {
+		seconds, _ := strconv.ParseFloat(string(m[1]), 64)
+		result.AvgLatency = time.Duration(seconds * float64(time.Second))
+	}
+	return result, nil
+}
+
+func parseWrkUnit(value float64, unit string) time.Duration {
+	switch unit {
+	case "us":
+		return time.Duration(value * float64(time.Microsecond))
+	case "ms":
+		return time.Duration(value * float64(time.Millisecond))
+	default:
+		return time.Duration(value * float64(time.Second))
+	}
+}
+
+func errFromOutput(tool string, err error, output []byte) error {
+	return &wrkRunError{tool: tool, cause: err, output: string(output)}
+}
+
+type wrkRunError struct {
+	tool   string
+	cause  error
+	output string
+}
+
+func (e *wrkRunError) Error() string {
+	return e.tool + " run failed: " + e.cause.Error() + "\n" + e.output
+}
+
+func (e *wrkRunError) Unwrap() error { return e.cause }
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// shellescape single-quotes s so it can be embedded verbatim as one shell
+// word, even if it contains single quotes, newlines, or other shell
+// metacharacters.
+func shellescape(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// curlForRequest renders req (plus the body that was about to be sent) as a
+// copy-pasteable curl invocation, so a failing table entry can be reproduced
+// directly against the C++ server without re-running the whole suite.
+func curlForRequest(req *http.Request, body []byte) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "curl -X %s %s --http1.1", shellescape(req.Method), shellescape(req.URL.String()))
+	if req.URL.Scheme == "https" {
+		b.WriteString(" -k")
+	}
+
+	headers := []string{"Host: " + req.Host}
+	for name, values := range req.Header {
+		for _, v := range values {
+			headers = append(headers, name+": "+v)
+		}
+	}
+	sort.Strings(headers)
+	for _, h := range headers {
+		fmt.Fprintf(&b, " -H %s", shellescape(h))
+	}
+
+	if len(body) > 0 {
+		b.WriteString(" --data-binary @- <<'EOF'\n")
+		b.Write(body)
+		if !bytes.HasSuffix(body, []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		b.WriteString("EOF")
+	}
+
+	return b.String()
+}
+
+// curlForMultipart renders a -F-based curl invocation for an upload whose
+// file content only ever existed in memory as part of a multipartTestCase;
+// path should point at a temp file the caller spooled the content into.
+func curlForMultipart(method, url, field, path string) string {
+	return fmt.Sprintf("curl -X %s %s --http1.1 -F %s", shellescape(method), shellescape(url), shellescape(field+"=@"+path))
+}
+
+// logCurlRepro spools content to a temp file and logs a curl -F reproduction
+// for it, gated on the subtest having failed or -v being passed. It's a
+// no-op otherwise, so passing runs don't litter the log with temp paths.
+func logCurlRepro(t *testing.T, method, url, field, fileName, content string) {
+	t.Helper()
+
+	if !t.Failed() && !testing.Verbose() {
+		return
+	}
+
+	f, err := os.CreateTemp("", "webserv-upload-*-"+fileName)
+	if err != nil {
+		t.Logf("reproduce: could not spool %s to a temp file: %v", fileName, err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Logf("reproduce: could not write %s: %v", f.Name(), err)
+		return
+	}
+
+	t.Logf("reproduce with:\n%s", curlForMultipart(method, url, field, f.Name()))
+}
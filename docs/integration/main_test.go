@@ -28,6 +28,13 @@ var (
 	testClient    *http.Client
 )
 
+// getTestURL returns the URL webserv is reachable at for path, the
+// convention nginx_comparison_test.go and tests/integration use to address
+// the webserv instance TestMain starts.
+func getTestURL(path string) string {
+	return fmt.Sprintf("http://%s:%d%s", testHost, testPort, path)
+}
+
 // TestMain manages the server lifecycle for all tests
 func TestMain(m *testing.M) {
 	// Setup: Build and start the server
@@ -434,6 +441,15 @@ func sendRequest(t *testing.T, method, uri, body string, headers map[string]stri
 		req.Header.Set(key, value)
 	}
 
+	// Print a copy-pasteable curl reproduction if this request's assertions
+	// end up failing (or -v is set), so a failing table entry can be
+	// replayed directly against the C++ server.
+	t.Cleanup(func() {
+		if t.Failed() || testing.Verbose() {
+			t.Logf("reproduce with:\n%s", curlForRequest(req, []byte(body)))
+		}
+	})
+
 	// Send request using test client
 	resp, err := testClient.Do(req)
 	if err != nil {
@@ -512,6 +528,10 @@ func TestWebservMultipartUpload(t *testing.T) {
 			}
 			req.Header.Set("Content-Type", writer.FormDataContentType())
 
+			t.Cleanup(func() {
+				logCurlRepro(t, req.Method, req.URL.String(), tc.fieldName, tc.fileName, tc.fileContent)
+			})
+
 			resp, err := testClient.Do(req)
 			if err != nil {
 				t.Fatalf("Failed to send request: %v", err)
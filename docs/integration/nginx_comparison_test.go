@@ -2,43 +2,259 @@ package integration
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/umarudotdev/selfserv/docs/integration/benchmark"
+	"github.com/umarudotdev/selfserv/docs/integration/try"
 )
 
 const (
 	nginxPort     = 8080
 	nginxConfPath = "nginx.conf"
 	nginxPidFile  = "nginx.pid"
+
+	// nginxTLSPort is the local provider's fixed HTTPS port. The container
+	// provider instead maps 443/tcp to whatever host port Docker assigns.
+	nginxTLSPort = 8443
 )
 
-// NginxComparison manages nginx instance for comparison testing
+// NginxProvider is how an nginx instance for comparison testing is
+// obtained. nginxLocalProvider runs a locally installed binary;
+// nginxContainerProvider runs the official nginx Docker image via
+// testcontainers-go. Both let TestComparisonWithNginx and friends run
+// unmodified regardless of which one backs a given NginxComparison.
+type NginxProvider interface {
+	Available() bool
+	Start() error
+	Stop() error
+	URL(path string) string
+	Addr() string // host:port, for tests that need a raw TCP dial
+
+	TLSURL(path string) string // https:// URL, for TLS parity testing
+	TLSAddr() string           // host:port of the TLS listener
+}
+
+// NginxComparison manages an nginx instance for comparison testing,
+// delegating the actual process/container lifecycle to a NginxProvider.
 type NginxComparison struct {
+	provider NginxProvider
+}
+
+// NewNginxComparison picks a provider automatically: a Docker-backed
+// container if the daemon is reachable, falling back to a local nginx
+// binary otherwise. This lets the same integration tests run unmodified
+// on a developer laptop without nginx installed and in a CI runner
+// without Docker or root privileges.
+func NewNginxComparison(workDir string) *NginxComparison {
+	container := newNginxContainerProvider(workDir)
+	if container.Available() {
+		return NewNginxComparisonWithProvider(container)
+	}
+	return NewNginxComparisonWithProvider(newNginxLocalProvider(workDir))
+}
+
+// NewNginxComparisonWithProvider builds a NginxComparison around an
+// explicit provider, for tests that need to force the container or
+// local-binary path rather than letting NewNginxComparison auto-detect.
+func NewNginxComparisonWithProvider(provider NginxProvider) *NginxComparison {
+	return &NginxComparison{provider: provider}
+}
+
+// NewNginxComparisonWithImage builds a container-backed NginxComparison
+// pinned to an explicit image, bypassing NewNginxComparison's local-binary
+// fallback. Used by TestNginxVersionMatrix to run identical scenarios
+// against every pinned nginx release.
+func NewNginxComparisonWithImage(workDir, image string) *NginxComparison {
+	return NewNginxComparisonWithProvider(newNginxContainerProviderWithImage(workDir, image))
+}
+
+// NewNginxComparisonWithSNIHosts builds a container-backed NginxComparison
+// whose TLS listener routes by SNI, serving each of hosts its own
+// CA-signed leaf certificate instead of the single "localhost" cert the
+// default provider uses. Used by TestNginxTLSBaseline's SNI coverage.
+func NewNginxComparisonWithSNIHosts(workDir string, hosts []string) *NginxComparison {
+	return NewNginxComparisonWithProvider(newNginxContainerProviderWithSNIHosts(workDir, hosts))
+}
+
+// sniProvider is implemented by NginxProviders that support SNI-routed TLS,
+// i.e. nginxContainerProvider when built via
+// newNginxContainerProviderWithSNIHosts. NginxComparison type-asserts
+// against it so nginxLocalProvider doesn't need any SNI-specific methods.
+type sniProvider interface {
+	TLSURLForHost(host, path string) string
+	CAPool() *x509.CertPool
+}
+
+// GetTLSURLForHost returns nginx's https:// URL for path on the given SNI
+// host. Only meaningful when the underlying provider was built via
+// NewNginxComparisonWithSNIHosts.
+func (n *NginxComparison) GetTLSURLForHost(host, path string) string {
+	sni, ok := n.provider.(sniProvider)
+	if !ok {
+		return n.GetTLSURL(path)
+	}
+	return sni.TLSURLForHost(host, path)
+}
+
+// TLSCAPool returns the CA pool that signed the underlying provider's SNI
+// leaf certificates, or nil if it wasn't built with SNI hosts.
+func (n *NginxComparison) TLSCAPool() *x509.CertPool {
+	sni, ok := n.provider.(sniProvider)
+	if !ok {
+		return nil
+	}
+	return sni.CAPool()
+}
+
+// TLSClientForHost returns an *http.Client that dials addr directly but
+// presents serverName in the TLS ClientHello's SNI extension, verifying
+// the response against pool. This lets a test reach a specific SNI
+// virtual host without needing DNS or /etc/hosts entries for it.
+func TLSClientForHost(addr, serverName string, pool *x509.CertPool) *http.Client {
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: serverName, RootCAs: pool}}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialTLSContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+// IsAvailable checks if nginx is available for testing.
+func (n *NginxComparison) IsAvailable() bool {
+	return n.provider.Available()
+}
+
+// Start starts the nginx instance.
+func (n *NginxComparison) Start() error {
+	return n.provider.Start()
+}
+
+// Stop stops the nginx instance.
+func (n *NginxComparison) Stop() error {
+	return n.provider.Stop()
+}
+
+// GetURL returns the nginx URL for a given path.
+func (n *NginxComparison) GetURL(path string) string {
+	return n.provider.URL(path)
+}
+
+// Addr returns nginx's host:port, for tests that dial it directly instead
+// of going through net/http.
+func (n *NginxComparison) Addr() string {
+	return n.provider.Addr()
+}
+
+// Host returns nginx's host, without the port, for building a Host header
+// on a connection opened against Addr.
+func (n *NginxComparison) Host() string {
+	host, _, err := net.SplitHostPort(n.Addr())
+	if err != nil {
+		return n.Addr()
+	}
+	return host
+}
+
+// GetTLSURL returns nginx's https:// URL for a given path.
+func (n *NginxComparison) GetTLSURL(path string) string {
+	return n.provider.TLSURL(path)
+}
+
+// TLSAddr returns nginx's TLS listener host:port, for tests that dial it
+// directly instead of going through net/http.
+func (n *NginxComparison) TLSAddr() string {
+	return n.provider.TLSAddr()
+}
+
+// fastCGIProvider is implemented by NginxProviders that can front real CGI
+// fixture scripts through fcgiwrap, i.e. nginxLocalProvider. NginxComparison
+// type-asserts against it so nginxContainerProvider doesn't need a
+// fcgiwrap binary baked into its image.
+type fastCGIProvider interface {
+	EnableFastCGI() bool
+	IsFastCGIEnabled() bool
+}
+
+// EnableFastCGI arranges for the underlying provider to front the
+// test-server/cgi-bin/ fixture scripts through a real fcgiwrap process at
+// /fcgi-bin/, if the provider supports it and fcgiwrap is installed.
+// Returns false otherwise, so callers can skip the real-CGI tests
+// gracefully. Must be called before Start.
+func (n *NginxComparison) EnableFastCGI() bool {
+	fcgi, ok := n.provider.(fastCGIProvider)
+	if !ok {
+		return false
+	}
+	return fcgi.EnableFastCGI()
+}
+
+// IsFastCGIEnabled reports whether EnableFastCGI succeeded.
+func (n *NginxComparison) IsFastCGIEnabled() bool {
+	fcgi, ok := n.provider.(fastCGIProvider)
+	if !ok {
+		return false
+	}
+	return fcgi.IsFastCGIEnabled()
+}
+
+// nginxLocalProvider runs nginx as a local child process, listening on the
+// fixed nginxPort. This was the only way to run the comparison nginx
+// before container support was added; it remains the fallback when Docker
+// isn't reachable.
+type nginxLocalProvider struct {
 	workDir    string
 	nginxPath  string
 	configPath string
 	pidFile    string
+	tlsCert    string
+	tlsKey     string
 	running    bool
+
+	// fcgiwrapPath, fcgiwrapSocket, and fcgiwrapProcess are set by
+	// EnableFastCGI; when fcgiwrapSocket is non-empty, GenerateConfig adds
+	// a /fcgi-bin/ location fastcgi_passing to it and Start/Stop manage
+	// its lifecycle alongside nginx's own.
+	fcgiwrapPath    string
+	fcgiwrapSocket  string
+	fcgiwrapProcess *exec.Cmd
 }
 
-// NewNginxComparison creates a new nginx comparison instance
-func NewNginxComparison(workDir string) *NginxComparison {
-	return &NginxComparison{
+// newNginxLocalProvider creates a local-binary nginx provider rooted at
+// workDir.
+func newNginxLocalProvider(workDir string) *nginxLocalProvider {
+	return &nginxLocalProvider{
 		workDir:    workDir,
 		nginxPath:  findNginxBinary(),
 		configPath: filepath.Join(workDir, nginxConfPath),
 		pidFile:    filepath.Join(workDir, nginxPidFile),
+		tlsCert:    filepath.Join(workDir, "tls.crt"),
+		tlsKey:     filepath.Join(workDir, "tls.key"),
 		running:    false,
 	}
 }
@@ -66,13 +282,132 @@ func findNginxBinary() string {
 	return ""
 }
 
-// IsAvailable checks if nginx is available for testing
-func (n *NginxComparison) IsAvailable() bool {
+// Available checks if a local nginx binary was found.
+func (n *nginxLocalProvider) Available() bool {
 	return n.nginxPath != ""
 }
 
-// GenerateConfig creates nginx configuration for comparison testing
-func (n *NginxComparison) GenerateConfig() error {
+// findFcgiwrapBinary locates fcgiwrap, the generic CGI-to-FastCGI bridge
+// EnableFastCGI uses to give nginx a real script interpreter to
+// fastcgi_pass to.
+func findFcgiwrapBinary() string {
+	candidates := []string{"/usr/sbin/fcgiwrap", "/usr/bin/fcgiwrap"}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	if path, err := exec.LookPath("fcgiwrap"); err == nil {
+		return path
+	}
+	return ""
+}
+
+// EnableFastCGI locates fcgiwrap and, if found, arranges for Start to spawn
+// it listening on a unix socket in workDir and for GenerateConfig to
+// fastcgi_pass /fcgi-bin/ requests to it. Returns false (not an error) if
+// fcgiwrap isn't installed, so callers can skip the real-CGI tests
+// gracefully.
+func (n *nginxLocalProvider) EnableFastCGI() bool {
+	n.fcgiwrapPath = findFcgiwrapBinary()
+	if n.fcgiwrapPath == "" {
+		return false
+	}
+	n.fcgiwrapSocket = filepath.Join(n.workDir, "fcgiwrap.sock")
+	return true
+}
+
+// IsFastCGIEnabled reports whether EnableFastCGI found fcgiwrap.
+func (n *nginxLocalProvider) IsFastCGIEnabled() bool {
+	return n.fcgiwrapPath != ""
+}
+
+// cgiBinDir returns the absolute path fcgiwrap should resolve
+// SCRIPT_FILENAME against: the fixture scripts committed under
+// test-server/cgi-bin/.
+func (n *nginxLocalProvider) cgiBinDir() string {
+	abs, err := filepath.Abs(filepath.Join(n.workDir, "cgi-bin"))
+	if err != nil {
+		return filepath.Join(n.workDir, "cgi-bin") + "/"
+	}
+	return abs + "/"
+}
+
+// GenerateConfig creates nginx configuration for comparison testing,
+// including a fresh self-signed certificate for the TLS listener.
+func (n *nginxLocalProvider) GenerateConfig() error {
+	if err := generateSelfSignedCert(n.tlsCert, n.tlsKey, "localhost"); err != nil {
+		return fmt.Errorf("failed to generate TLS certificate: %v", err)
+	}
+	return writeNginxConfig(n.configPath, n.pidFile, nginxPort, filepath.Join(n.workDir, "www"), nginxTLSPort, n.tlsCert, n.tlsKey,
+		n.fcgiwrapSocket, n.cgiBinDir())
+}
+
+// generateSelfSignedCert writes a minimal self-signed leaf certificate and
+// its private key to certFile/keyFile, good for exactly this test run.
+func generateSelfSignedCert(certFile, keyFile, host string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	certOut, err := os.Create(certFile)
+	if err != nil {
+		return err
+	}
+	defer certOut.Close()
+	if err := pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return err
+	}
+
+	keyOut, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer keyOut.Close()
+	return pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// writeNginxConfig renders the minimal nginx config shared by both
+// providers - the pid file, listen port, document root, and TLS listener
+// vary between a locally installed binary and the containerized image -
+// and writes it to configPath. When fcgiwrapSocket is non-empty, it adds a
+// /fcgi-bin/ location that fastcgi_passes to it, fronting the real CGI
+// fixture scripts under cgiBinDir rather than the static /cgi-bin/
+// simulation above - see nginxLocalProvider.EnableFastCGI.
+func writeNginxConfig(configPath, pidFile string, port int, root string, tlsPort int, tlsCert, tlsKey string, fcgiwrapSocket, cgiBinDir string) error {
+	var fcgiBlock string
+	if fcgiwrapSocket != "" {
+		fcgiBlock = fmt.Sprintf(`
+        # Real CGI execution via fcgiwrap, fronted by nginx's FastCGI client
+        location /fcgi-bin/ {
+            fastcgi_pass unix:%s;
+            fastcgi_param SCRIPT_FILENAME %s$fastcgi_script_name;
+            fastcgi_param REQUEST_METHOD $request_method;
+            fastcgi_param QUERY_STRING $query_string;
+            fastcgi_param CONTENT_TYPE $content_type;
+            fastcgi_param CONTENT_LENGTH $content_length;
+            fastcgi_read_timeout 3s;
+            include fastcgi_params;
+        }
+`, fcgiwrapSocket, cgiBinDir)
+	}
+
 	// Create a minimal nginx config that should work in most environments
 	config := fmt.Sprintf(`
 # Minimal nginx configuration for webserv comparison testing
@@ -107,6 +442,13 @@ http {
     keepalive_timeout 65;
     server_tokens off;
 
+    # Matches the MIME-type allowlist and Vary behavior a selfserv
+    # response-encoding layer would need to replicate.
+    gzip on;
+    gzip_types text/html text/css application/javascript text/plain;
+    gzip_vary on;
+    gzip_min_length 1;
+
     server {
         listen %d default_server;
         server_name localhost example.local test.local _;
@@ -149,6 +491,7 @@ http {
             return 200 "CGI Test Successful";
             add_header Content-Type text/html;
         }
+%s
 
         # API endpoint
         location /api {
@@ -171,21 +514,122 @@ http {
             internal;
         }
     }
+
+    server {
+        listen %d ssl;
+        server_name localhost;
+        ssl_certificate %s;
+        ssl_certificate_key %s;
+        ssl_protocols TLSv1.2 TLSv1.3;
+
+        root %s;
+        index index.html index.htm;
+
+        add_header Strict-Transport-Security "max-age=31536000" always;
+
+        location / {
+            try_files $uri $uri/ =404;
+        }
+    }
+}
+`,
+		pidFile,
+		port,
+		root,
+		fcgiBlock,
+		tlsPort,
+		tlsCert,
+		tlsKey,
+		root)
+
+	return os.WriteFile(configPath, []byte(config), 0644)
+}
+
+// writeNginxSNIConfig is like writeNginxConfig but renders a TLS server
+// block per entry in hosts, each selected by the TLS ClientHello's SNI
+// extension and served its own leaf certificate from tlsDir, instead of
+// the single always-on "localhost" certificate. Connections presenting an
+// unrecognized SNI name hit the default_server block and are rejected
+// outright.
+func writeNginxSNIConfig(configPath, pidFile string, port int, root string, tlsPort int, hosts []string, tlsDir string) error {
+	var tlsServers strings.Builder
+	tlsServers.WriteString(fmt.Sprintf(`    server {
+        listen %d ssl default_server;
+        server_name _;
+        ssl_reject_handshake on;
+    }
+`, tlsPort))
+	for _, host := range hosts {
+		tlsServers.WriteString(fmt.Sprintf(`
+    server {
+        listen %d ssl;
+        server_name %s;
+        ssl_certificate %s/%s.crt;
+        ssl_certificate_key %s/%s.key;
+        ssl_protocols TLSv1.2 TLSv1.3;
+
+        root %s;
+        index index.html index.htm;
+
+        location / {
+            try_files $uri $uri/ =404;
+        }
+    }
+`, tlsPort, host, tlsDir, host, tlsDir, host, root))
+	}
+
+	config := fmt.Sprintf(`
+# Minimal nginx configuration for SNI-routed TLS comparison testing
+daemon off;
+error_log stderr info;
+pid %s;
+
+events {
+    worker_connections 1024;
 }
+
+http {
+    default_type application/octet-stream;
+    access_log off;
+    sendfile on;
+    keepalive_timeout 65;
+    server_tokens off;
+
+    server {
+        listen %d default_server;
+        server_name _;
+        return 404;
+    }
+
+%s}
 `,
-		n.pidFile,
-		nginxPort,
-		filepath.Join(n.workDir, "www"))
+		pidFile,
+		port,
+		tlsServers.String())
 
-	return os.WriteFile(n.configPath, []byte(config), 0644)
+	return os.WriteFile(configPath, []byte(config), 0644)
 }
 
 // Start starts the nginx server
-func (n *NginxComparison) Start() error {
-	if !n.IsAvailable() {
+func (n *nginxLocalProvider) Start() error {
+	if !n.Available() {
 		return fmt.Errorf("nginx binary not found")
 	}
 
+	if n.fcgiwrapSocket != "" {
+		os.Remove(n.fcgiwrapSocket)
+		n.fcgiwrapProcess = exec.Command(n.fcgiwrapPath, "-s", "unix:"+n.fcgiwrapSocket)
+		if err := n.fcgiwrapProcess.Start(); err != nil {
+			return fmt.Errorf("failed to start fcgiwrap: %w", err)
+		}
+		for i := 0; i < 20; i++ {
+			if _, err := os.Stat(n.fcgiwrapSocket); err == nil {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
 	if err := n.GenerateConfig(); err != nil {
 		return fmt.Errorf("failed to generate nginx config: %v", err)
 	}
@@ -215,18 +659,13 @@ func (n *NginxComparison) Start() error {
 		return fmt.Errorf("failed to start nginx: %v", err)
 	}
 
-	// Give nginx a moment to start
-	time.Sleep(500 * time.Millisecond)
-
-	// Check if process is still running
+	// Wait for nginx to actually accept connections, backing off
+	// exponentially instead of a fixed sleep plus a fixed-count poll.
 	if cmd.Process != nil {
-		// Process started, now check if it's responding
-		for i := 0; i < 20; i++ { // Increased attempts
-			if n.isResponding() {
-				n.running = true
-				return nil
-			}
-			time.Sleep(100 * time.Millisecond)
+		if resp, err := try.GetRequest(n.URL("/"), 5*time.Second); err == nil {
+			resp.Body.Close()
+			n.running = true
+			return nil
 		}
 	}
 
@@ -241,7 +680,7 @@ func (n *NginxComparison) Start() error {
 }
 
 // Stop stops the nginx server
-func (n *NginxComparison) Stop() error {
+func (n *nginxLocalProvider) Stop() error {
 	if !n.running {
 		return nil
 	}
@@ -255,6 +694,12 @@ func (n *NginxComparison) Stop() error {
 		exec.Command(n.nginxPath, "-c", absConfigPath, "-s", "stop").Run()
 	}
 
+	if n.fcgiwrapProcess != nil {
+		n.fcgiwrapProcess.Process.Kill()
+		n.fcgiwrapProcess.Wait()
+		os.Remove(n.fcgiwrapSocket)
+	}
+
 	n.running = false
 
 	// Clean up files
@@ -264,121 +709,555 @@ func (n *NginxComparison) Stop() error {
 	return nil
 }
 
-// isResponding checks if nginx is responding to requests
-func (n *NginxComparison) isResponding() bool {
-	client := &http.Client{Timeout: 1 * time.Second}
-	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/", nginxPort))
-	if err != nil {
-		return false
+// URL returns nginx's URL for a given path.
+func (n *nginxLocalProvider) URL(path string) string {
+	return fmt.Sprintf("http://localhost:%d%s", nginxPort, path)
+}
+
+// Addr returns nginx's host:port.
+func (n *nginxLocalProvider) Addr() string {
+	return fmt.Sprintf("localhost:%d", nginxPort)
+}
+
+// TLSURL returns nginx's https:// URL for a given path.
+func (n *nginxLocalProvider) TLSURL(path string) string {
+	return fmt.Sprintf("https://localhost:%d%s", nginxTLSPort, path)
+}
+
+// TLSAddr returns nginx's TLS listener host:port.
+func (n *nginxLocalProvider) TLSAddr() string {
+	return fmt.Sprintf("localhost:%d", nginxTLSPort)
+}
+
+// nginxContainerProvider runs the official nginx Docker image via
+// testcontainers-go, mounting a generated nginx.conf and www/ document
+// root and exposing nginx's container port 80 through a dynamically
+// assigned host port.
+type nginxContainerProvider struct {
+	workDir   string
+	image     string
+	container testcontainers.Container
+	host      string
+	port      string
+	tlsPort   string
+	tlsCert   string
+	tlsKey    string
+
+	// sniHosts, when non-empty, switches GenerateConfig from the single
+	// "localhost" TLS listener to one `listen 443 ssl` server block per
+	// host, each with its own CA-signed leaf certificate - see
+	// newNginxContainerProviderWithSNIHosts.
+	sniHosts []string
+	sniCerts *sniCertBundle
+}
+
+// defaultNginxImage is the image newNginxContainerProvider pins to when no
+// explicit version is requested.
+const defaultNginxImage = "nginx:stable"
+
+// newNginxContainerProvider creates a container-backed nginx provider
+// rooted at workDir, where GenerateConfig below writes nginx.conf, www/,
+// and the TLS certificate before the container mounts them.
+func newNginxContainerProvider(workDir string) *nginxContainerProvider {
+	return newNginxContainerProviderWithImage(workDir, defaultNginxImage)
+}
+
+// newNginxContainerProviderWithImage is like newNginxContainerProvider but
+// pins an explicit image, letting TestNginxVersionMatrix run the same
+// scenarios against every entry in nginxVersionMatrix.
+func newNginxContainerProviderWithImage(workDir, image string) *nginxContainerProvider {
+	return &nginxContainerProvider{
+		workDir: workDir,
+		image:   image,
+		tlsCert: filepath.Join(workDir, "tls.crt"),
+		tlsKey:  filepath.Join(workDir, "tls.key"),
 	}
-	resp.Body.Close()
-	return true
 }
 
-// GetURL returns nginx URL for given path
-func (n *NginxComparison) GetURL(path string) string {
-	return fmt.Sprintf("http://localhost:%d%s", nginxPort, path)
+// newNginxContainerProviderWithSNIHosts creates a container-backed nginx
+// provider whose TLS listener routes by SNI: each entry in hosts gets its
+// own leaf certificate, signed by a temporary CA GenerateConfig generates,
+// instead of the single "localhost" self-signed cert the default provider
+// uses.
+func newNginxContainerProviderWithSNIHosts(workDir string, hosts []string) *nginxContainerProvider {
+	return &nginxContainerProvider{
+		workDir:  workDir,
+		image:    defaultNginxImage,
+		sniHosts: hosts,
+	}
 }
 
-// CompareResponse compares webserv response with nginx response
-type ResponseComparison struct {
-	Path           string
-	Method         string
-	WebservStatus  int
-	NginxStatus    int
-	StatusMatch    bool
-	HeaderMatches  map[string]bool
-	BodyMatch      bool
-	WebservBody    string
-	NginxBody      string
-	Notes          []string
-}
-
-// CompareResponses compares webserv and nginx responses for a given request
-func CompareResponses(webservURL, nginxURL, method string, body io.Reader) (*ResponseComparison, error) {
-	client := &http.Client{Timeout: 5 * time.Second}
+// sniCertBundle holds a temporary CA and the per-host leaf certificates it
+// signed, as generated by generateSNICerts.
+type sniCertBundle struct {
+	caPool *x509.CertPool
+	hosts  []string
+}
+
+// generateSNICerts creates a temporary CA under dir, then one leaf
+// certificate per host signed by that CA, writing ca.crt and
+// <host>.crt/<host>.key into dir. The returned bundle's caPool can verify
+// any of the leaf certificates.
+func generateSNICerts(dir string, hosts []string) (*sniCertBundle, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create SNI TLS dir: %v", err)
+	}
 
-	// Make request to webserv
-	webservReq, err := http.NewRequest(method, webservURL, body)
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "webserv comparison test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
 	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %v", err)
+	}
+	if err := writePEM(filepath.Join(dir, "ca.crt"), "CERTIFICATE", caDER); err != nil {
 		return nil, err
 	}
 
-	webservResp, err := client.Do(webservReq)
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+
+	for i, host := range hosts {
+		leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate leaf key for %s: %v", host, err)
+		}
+		leafTemplate := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i) + 2),
+			Subject:      pkix.Name{CommonName: host},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			DNSNames:     []string{host},
+		}
+		leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create leaf certificate for %s: %v", host, err)
+		}
+		if err := writePEM(filepath.Join(dir, host+".crt"), "CERTIFICATE", leafDER); err != nil {
+			return nil, err
+		}
+		if err := writePEM(filepath.Join(dir, host+".key"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(leafKey)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sniCertBundle{caPool: caPool, hosts: hosts}, nil
+}
+
+// writePEM writes der to path in the given PEM block type.
+func writePEM(path, blockType string, der []byte) error {
+	f, err := os.Create(path)
 	if err != nil {
-		return nil, fmt.Errorf("webserv request failed: %v", err)
+		return fmt.Errorf("failed to create %s: %v", path, err)
 	}
-	defer webservResp.Body.Close()
+	defer f.Close()
+	return pem.Encode(f, &pem.Block{Type: blockType, Bytes: der})
+}
 
-	webservBody, err := io.ReadAll(webservResp.Body)
+// Available reports whether a Docker daemon is reachable.
+func (n *nginxContainerProvider) Available() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	provider, err := testcontainers.NewDockerProvider()
 	if err != nil {
-		return nil, err
+		return false
 	}
+	defer provider.Close()
 
-	// Make request to nginx (reset body if needed)
-	var nginxBody []byte
-	var nginxResp *http.Response
+	return provider.Health(ctx) == nil
+}
 
-	if body != nil {
-		// For POST requests, we need a fresh body
-		// This is a limitation - in real tests we'd need to prepare the body twice
-		nginxReq, err := http.NewRequest(method, nginxURL, strings.NewReader(""))
+// GenerateConfig creates nginx configuration for comparison testing,
+// listening on the container's internal ports 80 and 443 regardless of
+// which host ports testcontainers maps them to, and generates the TLS
+// certificate the container mounts at /etc/nginx/tls.{crt,key}. When
+// sniHosts is set, it instead generates a per-host leaf certificate
+// bundle and a SNI-routed TLS config - see generateSNIConfig.
+func (n *nginxContainerProvider) GenerateConfig() error {
+	if len(n.sniHosts) > 0 {
+		return n.generateSNIConfig()
+	}
+	if err := generateSelfSignedCert(n.tlsCert, n.tlsKey, "localhost"); err != nil {
+		return fmt.Errorf("failed to generate TLS certificate: %v", err)
+	}
+	return writeNginxConfig(filepath.Join(n.workDir, nginxConfPath), "/var/run/nginx.pid", 80, "/usr/share/nginx/html",
+		443, "/etc/nginx/tls.crt", "/etc/nginx/tls.key", "", "")
+}
+
+// generateSNIConfig builds the CA and per-host leaf certificates sniHosts
+// needs, then renders an nginx config with one SNI-routed `listen 443
+// ssl` server block per host.
+func (n *nginxContainerProvider) generateSNIConfig() error {
+	certs, err := generateSNICerts(filepath.Join(n.workDir, "tls-sni"), n.sniHosts)
+	if err != nil {
+		return fmt.Errorf("failed to generate SNI certificates: %w", err)
+	}
+	n.sniCerts = certs
+	return writeNginxSNIConfig(filepath.Join(n.workDir, nginxConfPath), "/var/run/nginx.pid", 80, "/usr/share/nginx/html",
+		443, n.sniHosts, "/etc/nginx/tls-sni")
+}
+
+// Start generates the config, spins up the nginx container with the
+// generated config and document root bind-mounted in, and waits for it to
+// start serving.
+func (n *nginxContainerProvider) Start() error {
+	if err := n.GenerateConfig(); err != nil {
+		return fmt.Errorf("failed to generate nginx config: %v", err)
+	}
+
+	absConfigPath, err := filepath.Abs(filepath.Join(n.workDir, nginxConfPath))
+	if err != nil {
+		return fmt.Errorf("failed to get absolute config path: %v", err)
+	}
+	absWWWPath, err := filepath.Abs(filepath.Join(n.workDir, "www"))
+	if err != nil {
+		return fmt.Errorf("failed to get absolute www path: %v", err)
+	}
+	if err := os.MkdirAll(absWWWPath, 0755); err != nil {
+		return fmt.Errorf("failed to create www dir: %v", err)
+	}
+
+	mounts := testcontainers.ContainerMounts{
+		testcontainers.BindMount(absConfigPath, "/etc/nginx/nginx.conf"),
+		testcontainers.BindMount(absWWWPath, "/usr/share/nginx/html"),
+	}
+	if len(n.sniHosts) > 0 {
+		absTLSDir, err := filepath.Abs(filepath.Join(n.workDir, "tls-sni"))
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("failed to get absolute SNI TLS dir: %v", err)
 		}
-		nginxResp, err = client.Do(nginxReq)
+		mounts = append(mounts, testcontainers.BindMount(absTLSDir, "/etc/nginx/tls-sni"))
 	} else {
-		nginxReq, err := http.NewRequest(method, nginxURL, nil)
+		absCertPath, err := filepath.Abs(n.tlsCert)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("failed to get absolute TLS cert path: %v", err)
+		}
+		absKeyPath, err := filepath.Abs(n.tlsKey)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute TLS key path: %v", err)
 		}
-		nginxResp, err = client.Do(nginxReq)
+		mounts = append(mounts,
+			testcontainers.BindMount(absCertPath, "/etc/nginx/tls.crt"),
+			testcontainers.BindMount(absKeyPath, "/etc/nginx/tls.key"))
 	}
 
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        n.image,
+			ExposedPorts: []string{"80/tcp", "443/tcp"},
+			Mounts:       mounts,
+			WaitingFor:   wait.ForListeningPort("80/tcp").WithStartupTimeout(30 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start nginx container: %v", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get nginx container host: %v", err)
+	}
+	mappedPort, err := container.MappedPort(ctx, "80/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get nginx container port: %v", err)
+	}
+	mappedTLSPort, err := container.MappedPort(ctx, "443/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get nginx container TLS port: %v", err)
+	}
+
+	n.container = container
+	n.host = host
+	n.port = mappedPort.Port()
+	n.tlsPort = mappedTLSPort.Port()
+	return nil
+}
+
+// Stop terminates the nginx container.
+func (n *nginxContainerProvider) Stop() error {
+	if n.container == nil {
+		return nil
+	}
+	return n.container.Terminate(context.Background())
+}
+
+// URL returns nginx's URL for a given path, using the dynamically mapped
+// host port.
+func (n *nginxContainerProvider) URL(path string) string {
+	return fmt.Sprintf("http://%s:%s%s", n.host, n.port, path)
+}
+
+// Addr returns nginx's host:port, using the dynamically mapped host port.
+func (n *nginxContainerProvider) Addr() string {
+	return fmt.Sprintf("%s:%s", n.host, n.port)
+}
+
+// TLSURL returns nginx's https:// URL for a given path, using the
+// dynamically mapped host TLS port.
+func (n *nginxContainerProvider) TLSURL(path string) string {
+	return fmt.Sprintf("https://%s:%s%s", n.host, n.tlsPort, path)
+}
+
+// TLSAddr returns nginx's TLS listener host:port, using the dynamically
+// mapped host TLS port.
+func (n *nginxContainerProvider) TLSAddr() string {
+	return fmt.Sprintf("%s:%s", n.host, n.tlsPort)
+}
+
+// TLSURLForHost returns the https:// URL for path on the given SNI host,
+// using the dynamically mapped TLS host port. Only meaningful when the
+// provider was built via newNginxContainerProviderWithSNIHosts.
+func (n *nginxContainerProvider) TLSURLForHost(host, path string) string {
+	return fmt.Sprintf("https://%s:%s%s", host, n.tlsPort, path)
+}
+
+// CAPool returns the CA pool GenerateConfig generated to sign sniHosts'
+// leaf certificates, or nil if this provider wasn't built with SNI hosts.
+func (n *nginxContainerProvider) CAPool() *x509.CertPool {
+	if n.sniCerts == nil {
+		return nil
+	}
+	return n.sniCerts.caPool
+}
+
+// headerDiffAllowlist names response headers that are expected to differ
+// between webserv and nginx and so are never reported as a mismatch by
+// CompareResponses: Server identifies the software, Date is a timestamp,
+// and ETag is implementation-defined entity-tagging.
+var headerDiffAllowlist = map[string]bool{
+	"Server": true,
+	"Date":   true,
+	"ETag":   true,
+}
+
+// ResponseComparison is the result of diffing a webserv response against
+// nginx's for the same request. HeaderOnlyInWebserv/HeaderOnlyInNginx hold
+// headers present on only one side; HeaderValueDiffs holds headers present
+// on both sides with different values, excluding headerDiffAllowlist.
+type ResponseComparison struct {
+	Path                string
+	Method              string
+	WebservStatus       int
+	NginxStatus         int
+	StatusMatch         bool
+	HeaderOnlyInWebserv map[string]string
+	HeaderOnlyInNginx   map[string]string
+	HeaderValueDiffs    map[string][2]string
+	BodyMatch           bool
+	WebservBody         string
+	NginxBody           string
+	Notes               []string
+}
+
+// Diff renders a unified textual report of every mismatch CompareResponses
+// found, suitable for t.Log - failing assertions should point a reader
+// directly at the offending header or body bytes rather than just "status
+// didn't match".
+func (c *ResponseComparison) Diff() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", c.Method, c.Path)
+
+	if !c.StatusMatch {
+		fmt.Fprintf(&b, "- status: webserv=%d nginx=%d\n", c.WebservStatus, c.NginxStatus)
+	}
+
+	for _, header := range sortedKeys(c.HeaderOnlyInWebserv) {
+		fmt.Fprintf(&b, "- header %q only in webserv: %q\n", header, c.HeaderOnlyInWebserv[header])
+	}
+	for _, header := range sortedKeys(c.HeaderOnlyInNginx) {
+		fmt.Fprintf(&b, "- header %q only in nginx: %q\n", header, c.HeaderOnlyInNginx[header])
+	}
+	for _, header := range sortedHeaderDiffKeys(c.HeaderValueDiffs) {
+		values := c.HeaderValueDiffs[header]
+		fmt.Fprintf(&b, "- header %q differs: webserv=%q nginx=%q\n", header, values[0], values[1])
+	}
+
+	if !c.BodyMatch {
+		fmt.Fprintf(&b, "- body differs:\n  webserv: %q\n  nginx:   %q\n", c.WebservBody, c.NginxBody)
+	}
+
+	for _, note := range c.Notes {
+		fmt.Fprintf(&b, "- %s\n", note)
+	}
+
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedHeaderDiffKeys(m map[string][2]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mustRequest builds an *http.Request for use inside a try.Response retry
+// closure, where a constructor error (always static for a fixed
+// method/url/body triple) would otherwise have no way to propagate. Taking
+// body as []byte rather than io.Reader means the same bytes can be
+// replayed across retries and across the webserv/nginx pair.
+func mustRequest(method, url string, body []byte, headers map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		panic(fmt.Sprintf("nginx_comparison_test: building %s %s: %v", method, url, err))
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req
+}
+
+// CompareResponses sends an identical request (method, headers, body) to
+// webserv and nginx and diffs their responses. Passing body as []byte,
+// rather than a one-shot io.Reader, means both servers receive the same
+// payload and the request can be retried against either one.
+func CompareResponses(webservURL, nginxURL, method string, body []byte, headers map[string]string) (*ResponseComparison, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	webservResp, err := try.Response(3*time.Second, func() (*http.Response, error) {
+		return client.Do(mustRequest(method, webservURL, body, headers))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webserv request failed: %v", err)
+	}
+	defer webservResp.Body.Close()
+
+	webservBody, err := io.ReadAll(webservResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	nginxResp, err := try.Response(3*time.Second, func() (*http.Response, error) {
+		return client.Do(mustRequest(method, nginxURL, body, headers))
+	})
 	if err != nil {
 		return nil, fmt.Errorf("nginx request failed: %v", err)
 	}
 	defer nginxResp.Body.Close()
 
-	nginxBody, err = io.ReadAll(nginxResp.Body)
+	nginxBody, err := io.ReadAll(nginxResp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	// Compare responses
 	comparison := &ResponseComparison{
-		Path:           webservURL,
-		Method:         method,
-		WebservStatus:  webservResp.StatusCode,
-		NginxStatus:    nginxResp.StatusCode,
-		StatusMatch:    webservResp.StatusCode == nginxResp.StatusCode,
-		HeaderMatches:  make(map[string]bool),
-		WebservBody:    string(webservBody),
-		NginxBody:      string(nginxBody),
-		Notes:          []string{},
-	}
-
-	// Compare important headers
-	importantHeaders := []string{"Content-Type", "Content-Length", "Connection", "Server"}
-	for _, header := range importantHeaders {
-		webservVal := webservResp.Header.Get(header)
-		nginxVal := nginxResp.Header.Get(header)
-
-		// Special handling for Server header (expected to be different)
-		if header == "Server" {
-			comparison.HeaderMatches[header] = true // Always pass server header comparison
-			comparison.Notes = append(comparison.Notes,
-				fmt.Sprintf("Server headers differ (expected): webserv='%s', nginx='%s'", webservVal, nginxVal))
-		} else {
-			comparison.HeaderMatches[header] = webservVal == nginxVal
-			if webservVal != nginxVal {
-				comparison.Notes = append(comparison.Notes,
-					fmt.Sprintf("%s header differs: webserv='%s', nginx='%s'", header, webservVal, nginxVal))
-			}
+		Path:                webservURL,
+		Method:              method,
+		WebservStatus:       webservResp.StatusCode,
+		NginxStatus:         nginxResp.StatusCode,
+		StatusMatch:         webservResp.StatusCode == nginxResp.StatusCode,
+		HeaderOnlyInWebserv: make(map[string]string),
+		HeaderOnlyInNginx:   make(map[string]string),
+		HeaderValueDiffs:    make(map[string][2]string),
+		WebservBody:         string(webservBody),
+		NginxBody:           string(nginxBody),
+		Notes:               []string{},
+	}
+
+	for header := range webservResp.Header {
+		canonical := http.CanonicalHeaderKey(header)
+		if headerDiffAllowlist[canonical] {
+			continue
+		}
+		webservVal := webservResp.Header.Get(canonical)
+		nginxVal := nginxResp.Header.Get(canonical)
+		switch {
+		case nginxResp.Header.Get(canonical) == "" && len(nginxResp.Header.Values(canonical)) == 0:
+			comparison.HeaderOnlyInWebserv[canonical] = webservVal
+		case webservVal != nginxVal:
+			comparison.HeaderValueDiffs[canonical] = [2]string{webservVal, nginxVal}
+		}
+	}
+	for header := range nginxResp.Header {
+		canonical := http.CanonicalHeaderKey(header)
+		if headerDiffAllowlist[canonical] {
+			continue
+		}
+		if len(webservResp.Header.Values(canonical)) == 0 {
+			comparison.HeaderOnlyInNginx[canonical] = nginxResp.Header.Get(canonical)
 		}
 	}
 
-	// Simple body comparison (exact match not always expected due to different server implementations)
+	comparison.BodyMatch = strings.TrimSpace(string(webservBody)) == strings.TrimSpace(string(nginxBody))
+
+	return comparison, nil
+}
+
+// CompareResponsesTLS is the TLS analogue of CompareResponses: both URLs
+// are fetched over HTTPS with certificate verification disabled, since
+// both servers use an on-the-fly self-signed cert for this test run. It
+// isn't exercised by any test yet - webserv has no TLS listener in this
+// tree, see the skipped WebservTLSParity subtest in
+// TestProtocolComplianceComparison - but it's wired up so that subtest
+// only has to stop skipping once webserv gains one.
+func CompareResponsesTLS(webservURL, nginxURL, method string) (*ResponseComparison, error) {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	webservResp, err := client.Do(mustRequest(method, webservURL, nil, nil))
+	if err != nil {
+		return nil, fmt.Errorf("webserv TLS request failed: %v", err)
+	}
+	defer webservResp.Body.Close()
+	webservBody, err := io.ReadAll(webservResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	nginxResp, err := client.Do(mustRequest(method, nginxURL, nil, nil))
+	if err != nil {
+		return nil, fmt.Errorf("nginx TLS request failed: %v", err)
+	}
+	defer nginxResp.Body.Close()
+	nginxBody, err := io.ReadAll(nginxResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &ResponseComparison{
+		Path:                webservURL,
+		Method:              method,
+		WebservStatus:       webservResp.StatusCode,
+		NginxStatus:         nginxResp.StatusCode,
+		StatusMatch:         webservResp.StatusCode == nginxResp.StatusCode,
+		HeaderOnlyInWebserv: make(map[string]string),
+		HeaderOnlyInNginx:   make(map[string]string),
+		HeaderValueDiffs:    make(map[string][2]string),
+		WebservBody:         string(webservBody),
+		NginxBody:           string(nginxBody),
+		Notes:               []string{},
+	}
 	comparison.BodyMatch = strings.TrimSpace(string(webservBody)) == strings.TrimSpace(string(nginxBody))
 
 	return comparison, nil
@@ -396,34 +1275,41 @@ func TestComparisonWithNginx(t *testing.T) {
 	require.NoError(t, nginx.Start(), "Failed to start nginx for comparison")
 	defer nginx.Stop()
 
+	// Webserv is expected to already be running (see TestMain), but give
+	// it the same readiness grace period as nginx rather than assuming
+	// the first request lands after it's accepting connections.
+	webservResp, err := try.GetRequest(getTestURL("/"), 5*time.Second)
+	require.NoError(t, err, "webserv did not become ready")
+	webservResp.Body.Close()
+
 	tests := []struct {
-		name         string
-		path         string
-		method       string
+		name          string
+		path          string
+		method        string
 		expectSimilar bool
 	}{
 		{
-			name:         "Root GET request",
-			path:         "/",
-			method:       "GET",
+			name:          "Root GET request",
+			path:          "/",
+			method:        "GET",
 			expectSimilar: true,
 		},
 		{
-			name:         "404 Not Found",
-			path:         "/nonexistent.html",
-			method:       "GET",
+			name:          "404 Not Found",
+			path:          "/nonexistent.html",
+			method:        "GET",
 			expectSimilar: true,
 		},
 		{
-			name:         "Method not allowed",
-			path:         "/",
-			method:       "PATCH",
+			name:          "Method not allowed",
+			path:          "/",
+			method:        "PATCH",
 			expectSimilar: true,
 		},
 		{
-			name:         "Redirect test",
-			path:         "/old",
-			method:       "GET",
+			name:          "Redirect test",
+			path:          "/old",
+			method:        "GET",
 			expectSimilar: true,
 		},
 	}
@@ -433,21 +1319,11 @@ func TestComparisonWithNginx(t *testing.T) {
 			webservURL := getTestURL(tt.path)
 			nginxURL := nginx.GetURL(tt.path)
 
-			comparison, err := CompareResponses(webservURL, nginxURL, tt.method, nil)
+			comparison, err := CompareResponses(webservURL, nginxURL, tt.method, nil, nil)
 			require.NoError(t, err)
 
 			t.Logf("Comparison for %s %s:", tt.method, tt.path)
-			t.Logf("  Webserv status: %d", comparison.WebservStatus)
-			t.Logf("  Nginx status: %d", comparison.NginxStatus)
-			t.Logf("  Status match: %v", comparison.StatusMatch)
-
-			for header, match := range comparison.HeaderMatches {
-				t.Logf("  %s header match: %v", header, match)
-			}
-
-			if len(comparison.Notes) > 0 {
-				t.Logf("  Notes: %v", comparison.Notes)
-			}
+			t.Logf("%s", comparison.Diff())
 
 			if tt.expectSimilar {
 				assert.True(t, comparison.StatusMatch,
@@ -472,13 +1348,17 @@ func TestProtocolComplianceComparison(t *testing.T) {
 	require.NoError(t, nginx.Start())
 	defer nginx.Stop()
 
+	webservResp, err := try.GetRequest(getTestURL("/"), 5*time.Second)
+	require.NoError(t, err, "webserv did not become ready")
+	webservResp.Body.Close()
+
 	t.Run("HTTP/1.1 keep-alive behavior", func(t *testing.T) {
 		// Test keep-alive connections
-		webservConn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", testServerPort))
+		webservConn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", testPort))
 		require.NoError(t, err)
 		defer webservConn.Close()
 
-		nginxConn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", nginxPort))
+		nginxConn, err := net.Dial("tcp", nginx.Addr())
 		require.NoError(t, err)
 		defer nginxConn.Close()
 
@@ -537,6 +1417,119 @@ func TestProtocolComplianceComparison(t *testing.T) {
 		t.Logf("Webserv Date: %s", webservDate)
 		t.Logf("Nginx Date: %s", nginxDate)
 	})
+
+	t.Run("TLS parity", func(t *testing.T) {
+		t.Run("NginxTLSHandshakeSucceeds", func(t *testing.T) {
+			conn, err := tls.Dial("tcp", nginx.TLSAddr(), &tls.Config{InsecureSkipVerify: true})
+			require.NoError(t, err)
+			conn.Close()
+		})
+
+		t.Run("NginxALPNNegotiation", func(t *testing.T) {
+			conn, err := tls.Dial("tcp", nginx.TLSAddr(), &tls.Config{
+				InsecureSkipVerify: true,
+				NextProtos:         []string{"h2", "http/1.1"},
+			})
+			require.NoError(t, err)
+			defer conn.Close()
+
+			negotiated := conn.ConnectionState().NegotiatedProtocol
+			t.Logf("nginx negotiated ALPN protocol: %q", negotiated)
+			assert.Contains(t, []string{"", "http/1.1"}, negotiated,
+				"nginx's test config has no http2 directive, so ALPN should never settle on h2")
+		})
+
+		t.Run("NginxStrictTransportSecurityHeader", func(t *testing.T) {
+			client := &http.Client{
+				Timeout:   5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+			}
+			resp, err := client.Do(mustRequest("GET", nginx.GetTLSURL("/"), nil, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.NotEmpty(t, resp.Header.Get("Strict-Transport-Security"),
+				"expected Strict-Transport-Security header on nginx's HTTPS response")
+		})
+
+		t.Run("NginxRejectsPlaintextOnTLSPort", func(t *testing.T) {
+			conn, err := net.Dial("tcp", nginx.TLSAddr())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+			require.NoError(t, err)
+
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			require.NoError(t, err, "nginx's ssl listener should still speak plain HTTP/1.1 long enough to reject a plaintext request, not just drop the connection")
+			defer resp.Body.Close()
+
+			assert.Equal(t, 400, resp.StatusCode,
+				"nginx recognizes a plaintext HTTP request arriving on an ssl listener and responds 400 rather than attempting a TLS handshake")
+		})
+
+		t.Run("WebservTLSParity", func(t *testing.T) {
+			// webserv has no TLS/HTTPS listener checked into this tree, so
+			// there's nothing on its side to run CompareResponsesTLS
+			// against yet. Once it gains one, this should assert webserv
+			// and nginx agree on TLS handshake success, ALPN negotiation,
+			// and HSTS the way the subtests above do for nginx alone.
+			t.Skip("webserv has no TLS/HTTPS listener checked into this tree; nothing to compare against nginx's TLS surface yet")
+		})
+	})
+}
+
+// TestNginxTLSBaseline pins nginx's SNI-based virtual host routing: one
+// TLS listener serving a distinct leaf certificate per hostname, selected
+// by the ClientHello's SNI extension rather than the single always-on
+// "localhost" cert TestProtocolComplianceComparison's "TLS parity"
+// subtests exercise.
+func TestNginxTLSBaseline(t *testing.T) {
+	nginxTLSHosts := []string{"a.webserv-test.local", "b.webserv-test.local"}
+
+	nginx := NewNginxComparisonWithSNIHosts("test-server", nginxTLSHosts)
+
+	if !nginx.IsAvailable() {
+		t.Skip("Docker not available for SNI TLS testing")
+	}
+
+	require.NoError(t, nginx.Start())
+	defer nginx.Stop()
+
+	pool := nginx.TLSCAPool()
+	require.NotNil(t, pool, "SNI-backed NginxComparison should expose the CA pool it signed leaf certs with")
+
+	t.Run("BasicHTTPSGet", func(t *testing.T) {
+		client := TLSClientForHost(nginx.TLSAddr(), nginxTLSHosts[0], pool)
+		resp, err := client.Get(nginx.GetTLSURLForHost(nginxTLSHosts[0], "/"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.NotNil(t, resp.TLS)
+	})
+
+	t.Run("CertificateSelectionBySNI", func(t *testing.T) {
+		for _, host := range nginxTLSHosts {
+			host := host
+			t.Run(host, func(t *testing.T) {
+				client := TLSClientForHost(nginx.TLSAddr(), host, pool)
+				resp, err := client.Get(nginx.GetTLSURLForHost(host, "/"))
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				require.NotEmpty(t, resp.TLS.PeerCertificates)
+				assert.Equal(t, host, resp.TLS.PeerCertificates[0].Subject.CommonName,
+					"nginx should have selected the leaf certificate matching the SNI hostname presented in the ClientHello")
+			})
+		}
+	})
+
+	t.Run("MismatchedSNIRejected", func(t *testing.T) {
+		client := TLSClientForHost(nginx.TLSAddr(), "unconfigured.invalid", pool)
+		_, err := client.Get(nginx.GetTLSURLForHost("unconfigured.invalid", "/"))
+		assert.Error(t, err,
+			"nginx's default_server TLS block has ssl_reject_handshake on, so an unrecognized SNI hostname should fail the handshake")
+	})
 }
 
 // Test error handling comparison
@@ -575,7 +1568,7 @@ func TestErrorHandlingComparison(t *testing.T) {
 			webservURL := getTestURL(tt.path)
 			nginxURL := nginx.GetURL(tt.path)
 
-			comparison, err := CompareResponses(webservURL, nginxURL, tt.method, nil)
+			comparison, err := CompareResponses(webservURL, nginxURL, tt.method, nil, nil)
 			require.NoError(t, err)
 
 			t.Logf("Error handling comparison for %s %s:", tt.method, tt.path)
@@ -594,7 +1587,219 @@ func TestErrorHandlingComparison(t *testing.T) {
 	}
 }
 
+// rawExchange is the outcome of sending a raw request over a plain TCP
+// connection: the parsed status line, if any, and whether a body followed
+// it. statusCode is 0 when the response couldn't be parsed as HTTP at all
+// - typically because the server rejected the request by closing the
+// connection rather than sending a status line.
+type rawExchange struct {
+	statusCode int
+	hasBody    bool
+}
+
+// rejected reports whether the exchange looks like a malformed-request
+// rejection: RFC 7230 permits a server to respond with a 4xx status or to
+// simply close the connection without responding, and both count here.
+func (r rawExchange) rejected() bool {
+	return r.statusCode == 0 || (r.statusCode >= 400 && r.statusCode < 500)
+}
+
+// sendRaw dials addr, writes request verbatim, and reads whatever comes
+// back until either a full response is read, the deadline is reached, or
+// the connection is closed - all three are valid outcomes for the
+// malformed requests this test sends.
+func sendRaw(addr, request string, timeout time.Duration) rawExchange {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return rawExchange{}
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return rawExchange{}
+	}
+
+	raw, _ := io.ReadAll(conn)
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), nil)
+	if err != nil {
+		return rawExchange{}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return rawExchange{statusCode: resp.StatusCode, hasBody: len(body) > 0}
+}
+
+// TestWireLevelComparison speaks raw TCP to both servers (mirroring the
+// keep-alive subtest in TestProtocolComplianceComparison) and checks
+// RFC 7230 edge cases nginx is known to handle strictly: a chunked body
+// with a trailer, duplicate Content-Length, Transfer-Encoding and
+// Content-Length together, oversized headers, an oversized request line,
+// Expect: 100-continue, and HTTP/1.0 without a Host header. Cases whose
+// correct behavior is unambiguous from the RFC assert parity between
+// webserv and nginx; cases that depend on limits this tree doesn't
+// document (header/request-line size caps) only assert nginx's side and
+// log webserv's for visibility.
+func TestWireLevelComparison(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for wire-level comparison testing")
+	}
+
+	require.NoError(t, nginx.Start())
+	defer nginx.Stop()
+
+	webservResp, err := try.GetRequest(getTestURL("/"), 5*time.Second)
+	require.NoError(t, err, "webserv did not become ready")
+	webservResp.Body.Close()
+
+	webservAddr := fmt.Sprintf("%s:%d", testHost, testPort)
+	nginxAddr := nginx.Addr()
+
+	t.Run("ChunkedBodyWithTrailers", func(t *testing.T) {
+		request := "POST /upload HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\n" +
+			"5\r\nhello\r\n0\r\nX-Trailer: done\r\n\r\n"
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.Equal(t, nginx.statusCode, webserv.statusCode,
+			"a well-formed chunked body with a trailer should be accepted the same way by both servers")
+		assert.Equal(t, nginx.hasBody, webserv.hasBody)
+	})
+
+	t.Run("DuplicateContentLength", func(t *testing.T) {
+		request := "POST /upload HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nContent-Length: 5\r\n\r\nhello"
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.True(t, nginx.rejected(), "nginx should reject duplicate Content-Length headers per RFC 7230 3.3.2")
+		assert.True(t, webserv.rejected(), "webserv should reject duplicate Content-Length headers per RFC 7230 3.3.2")
+	})
+
+	t.Run("ChunkedAndContentLengthTogether", func(t *testing.T) {
+		request := "POST /upload HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n" +
+			"5\r\nhello\r\n0\r\n\r\n"
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.True(t, nginx.rejected(), "nginx should reject Transfer-Encoding and Content-Length together per RFC 7230 3.3.3")
+		assert.True(t, webserv.rejected(), "webserv should reject Transfer-Encoding and Content-Length together per RFC 7230 3.3.3")
+	})
+
+	t.Run("OversizedHeaders", func(t *testing.T) {
+		request := fmt.Sprintf("GET / HTTP/1.1\r\nHost: localhost\r\nX-Huge: %s\r\n\r\n", strings.Repeat("a", 64<<10))
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.True(t, nginx.rejected(), "nginx's default large_client_header_buffers should reject a 64KB header")
+		// webserv's header size limit, if any, isn't documented in this
+		// tree, so its behavior is only logged above, not asserted.
+	})
+
+	t.Run("OversizedRequestLine", func(t *testing.T) {
+		request := fmt.Sprintf("GET /%s HTTP/1.1\r\nHost: localhost\r\n\r\n", strings.Repeat("a", 64<<10))
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.True(t, nginx.rejected(), "nginx's default large_client_header_buffers should reject a 64KB request line")
+		// Same caveat as OversizedHeaders: webserv's request-line limit
+		// isn't documented in this tree.
+	})
+
+	t.Run("Expect100Continue", func(t *testing.T) {
+		conn, err := net.Dial("tcp", nginxAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(3 * time.Second))
+
+		_, err = conn.Write([]byte("POST /upload HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n"))
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(conn)
+		interim, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Contains(t, interim, "100", "nginx should send an interim 100 Continue response before the body arrives")
+
+		_, err = conn.Write([]byte("hello"))
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		t.Logf("nginx final status after 100-continue: %d", resp.StatusCode)
+
+		t.Run("Webserv", func(t *testing.T) {
+			// webserv's handling of Expect: 100-continue isn't documented
+			// in this tree, so there's no parity assertion to make yet -
+			// recorded as a skip rather than silently dropping this edge
+			// case from the harness.
+			t.Skip("webserv's Expect: 100-continue handling isn't documented in this tree; nothing to assert parity against yet")
+		})
+	})
+
+	t.Run("HTTP/1.0 without Host", func(t *testing.T) {
+		request := "GET / HTTP/1.0\r\n\r\n"
+
+		webserv := sendRaw(webservAddr, request, 3*time.Second)
+		nginx := sendRaw(nginxAddr, request, 3*time.Second)
+		t.Logf("webserv: status=%d hasBody=%v", webserv.statusCode, webserv.hasBody)
+		t.Logf("nginx:   status=%d hasBody=%v", nginx.statusCode, nginx.hasBody)
+
+		assert.Equal(t, http.StatusOK, nginx.statusCode,
+			"HTTP/1.0 doesn't require a Host header per RFC 7230 5.4; nginx's default_server should still serve it")
+		assert.Equal(t, nginx.statusCode, webserv.statusCode, "webserv should accept HTTP/1.0 without Host the same way nginx does")
+		assert.Equal(t, nginx.hasBody, webserv.hasBody)
+	})
+}
+
 // Benchmark comparison between webserv and nginx
+// benchmarkEndpoint is one route exercised by BenchmarkWebservVsNginx,
+// each compared between webserv and nginx under the same method and body.
+type benchmarkEndpoint struct {
+	name   string
+	path   string
+	method string
+	body   func() io.Reader
+}
+
+var benchmarkEndpoints = []benchmarkEndpoint{
+	{name: "Root", path: "/", method: "GET"},
+	{name: "Autoindex", path: "/public/", method: "GET"},
+	{name: "Upload1MB", path: "/upload", method: "POST", body: func() io.Reader {
+		return bytes.NewReader(bytes.Repeat([]byte("A"), 1<<20))
+	}},
+	{name: "CGI", path: "/cgi-bin/", method: "GET"},
+}
+
+// sampleDuration is how long each endpoint is sampled for, per server, per
+// measurement (RPS or CPS). Kept short since RPS runs for four endpoints
+// times two servers, plus one CPS sample per server.
+const sampleDuration = 2 * time.Second
+
+// BenchmarkWebservVsNginx is a structured throughput/latency regression
+// suite, modeled on hs-test's nginx perf tests: for each of /, /public/
+// (autoindex), /upload (1 MB POST) and /cgi-bin/, it samples
+// requests-per-second latency (BenchmarkRPS) against both webserv and
+// nginx, aggregates min/median/p95/p99/max/stddev via benchmark.Aggregate,
+// and writes both a JSON and a Markdown report comparing them side by
+// side. It also samples raw connections-per-second (BenchmarkCPS,
+// independent of request handling) and, if wrk or hey is installed, runs
+// an external load test for a second opinion.
 func BenchmarkWebservVsNginx(b *testing.B) {
 	nginx := NewNginxComparison("test-server")
 
@@ -605,27 +1810,75 @@ func BenchmarkWebservVsNginx(b *testing.B) {
 	require.NoError(b, nginx.Start())
 	defer nginx.Stop()
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	if _, err := try.GetRequest(getTestURL("/"), 5*time.Second); err != nil {
+		b.Fatalf("webserv did not become ready: %v", err)
+	}
 
-	b.Run("Webserv", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			resp, err := client.Get(getTestURL("/"))
-			if err != nil {
-				b.Fatal(err)
-			}
-			resp.Body.Close()
-		}
+	client := &http.Client{Timeout: 10 * time.Second}
+	report := &benchmark.Report{}
+
+	for _, ep := range benchmarkEndpoints {
+		ep := ep
+		b.Run(ep.name, func(b *testing.B) {
+			webservResult := benchmark.BenchmarkRPS(client, func() (*http.Request, error) {
+				return endpointRequest(ep, getTestURL(ep.path))
+			}, sampleDuration)
+
+			nginxResult := benchmark.BenchmarkRPS(client, func() (*http.Request, error) {
+				return endpointRequest(ep, nginx.GetURL(ep.path))
+			}, sampleDuration)
+
+			report.Results = append(report.Results, benchmark.EndpointResult{
+				Endpoint: ep.name,
+				Webserv:  webservResult.Stats,
+				Nginx:    nginxResult.Stats,
+			})
+
+			b.Logf("%s: webserv p95=%v (errors=%d), nginx p95=%v (errors=%d)",
+				ep.name, webservResult.Stats.P95, webservResult.Errors,
+				nginxResult.Stats.P95, nginxResult.Errors)
+		})
+	}
+
+	b.Run("ConnectionsPerSecond", func(b *testing.B) {
+		webservAddr := fmt.Sprintf("%s:%d", testHost, testPort)
+		webservCPS := benchmark.BenchmarkCPS(webservAddr, sampleDuration)
+		nginxCPS := benchmark.BenchmarkCPS(nginx.Addr(), sampleDuration)
+
+		b.Logf("webserv CPS: n=%d median=%v p99=%v errors=%d",
+			webservCPS.Stats.N, webservCPS.Stats.Median, webservCPS.Stats.P99, webservCPS.Errors)
+		b.Logf("nginx CPS: n=%d median=%v p99=%v errors=%d",
+			nginxCPS.Stats.N, nginxCPS.Stats.Median, nginxCPS.Stats.P99, nginxCPS.Errors)
 	})
 
-	b.Run("Nginx", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			resp, err := client.Get(nginx.GetURL("/"))
-			if err != nil {
-				b.Fatal(err)
-			}
-			resp.Body.Close()
+	b.Run("ExternalWrkTool", func(b *testing.B) {
+		result, err := benchmark.RunWrk(getTestURL("/"), sampleDuration, 10)
+		if errors.Is(err, benchmark.ErrNoExternalBenchmarkTool) {
+			b.Skip("neither wrk nor hey is installed")
 		}
+		require.NoError(b, err)
+		b.Logf("%s: %.0f req/sec, avg latency %v", result.Tool, result.RequestsPerSec, result.AvgLatency)
 	})
+
+	outDir := b.TempDir()
+	jsonPath := filepath.Join(outDir, "benchmark-report.json")
+	mdPath := filepath.Join(outDir, "benchmark-report.md")
+	if err := report.WriteJSONFile(jsonPath); err != nil {
+		b.Logf("failed to write JSON report: %v", err)
+	}
+	if err := report.WriteMarkdownFile(mdPath); err != nil {
+		b.Logf("failed to write Markdown report: %v", err)
+	}
+	b.Logf("wrote benchmark report to %s and %s", jsonPath, mdPath)
+}
+
+// endpointRequest builds a fresh *http.Request for ep against baseURL,
+// re-materializing the body (if any) so the same endpoint can be
+// requested repeatedly within a sampling loop.
+func endpointRequest(ep benchmarkEndpoint, baseURL string) (*http.Request, error) {
+	var body io.Reader
+	if ep.body != nil {
+		body = ep.body()
+	}
+	return http.NewRequest(ep.method, baseURL, body)
 }
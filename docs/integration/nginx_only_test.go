@@ -3,554 +3,552 @@ package integration
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
-)
 
-const (
-	nginxTestHost = "localhost"
-	nginxTestPort = 8080
-	requestTimeout = 10 * time.Second
+	"golang.org/x/time/rate"
+
+	"github.com/umarudotdev/selfserv/docs/integration/staticfiles"
 )
 
+// perfJSONPath, perfTargetRPS, and perfDuration let the performance
+// baseline be re-pointed at a different target/load shape without editing
+// the test, e.g. to run it head-to-head against webserv:
+//
+//	go test -run TestNginxPerformanceBaseline -args -perf.json=out.json -target-rps=5000 -duration=30s
 var (
-	nginxInstance *NginxComparison
-	nginxClient   *http.Client
+	perfJSONPath  = flag.String("perf.json", "", "optional path to write performance baseline results as JSON, for trend tracking across commits")
+	perfTargetRPS = flag.Int("target-rps", 1000, "target requests/sec the SustainedLoad case holds via a rate limiter")
+	perfDuration  = flag.Duration("duration", 10*time.Second, "wall-clock duration the SustainedLoad case runs for")
 )
 
-// NginxComparison manages nginx instance for testing
-type NginxComparison struct {
-	workDir    string
-	nginxPath  string
-	configPath string
-	pidFile    string
-	running    bool
-}
+// nginxVersionMatrix lists the additional pinned nginx releases
+// TestNginxVersionMatrix exercises the baseline scenarios against, so a new
+// version only needs to be added here to be covered.
+var nginxVersionMatrix = []string{"nginx:1.18", "nginx:1.24", "nginx:1.27"}
 
-// NewNginxComparison creates a new nginx comparison instance
-func NewNginxComparison(workDir string) *NginxComparison {
-	return &NginxComparison{
-		workDir:    workDir,
-		nginxPath:  findNginxBinary(),
-		configPath: filepath.Join(workDir, "nginx.conf"),
-		pidFile:    filepath.Join(workDir, "nginx.pid"),
-		running:    false,
-	}
-}
+// sendNginxRequest is a test helper for sending requests to nginx. It also
+// returns the *http.Request it built, so callers can pass it (with the
+// request body they already have) to curlForRequest on an assertion
+// failure.
+func sendNginxRequest(t *testing.T, nginx *NginxComparison, client *http.Client, method, uri, body string, headers map[string]string) (statusCode int, statusText, responseBody string, req *http.Request) {
+	t.Helper()
 
-// findNginxBinary attempts to locate nginx binary
-func findNginxBinary() string {
-	candidates := []string{
-		"/usr/sbin/nginx",
-		"/usr/bin/nginx",
-		"/usr/local/bin/nginx",
-		"/opt/nginx/sbin/nginx",
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
 	}
 
-	for _, path := range candidates {
-		if _, err := os.Stat(path); err == nil {
-			return path
-		}
+	url := nginx.GetURL(uri)
+	req, err := http.NewRequest(method, url, bodyReader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
 	}
 
-	// Try PATH
-	if path, err := exec.LookPath("nginx"); err == nil {
-		return path
+	// Add custom headers
+	for key, value := range headers {
+		req.Header.Set(key, value)
 	}
 
-	return ""
-}
+	// Send request using nginx client
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
 
-// IsAvailable checks if nginx is available for testing
-func (n *NginxComparison) IsAvailable() bool {
-	return n.nginxPath != ""
-}
+	// Read response body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
 
-// GenerateConfig creates nginx configuration for testing
-func (n *NginxComparison) GenerateConfig() error {
-	config := fmt.Sprintf(`
-# Nginx configuration for HTTP server baseline testing
-daemon off;
-error_log stderr info;
-pid %s;
-
-events {
-    worker_connections 1024;
-    use epoll;
+	return resp.StatusCode, resp.Status, string(bodyBytes), req
 }
 
-http {
-    # Basic MIME types
-    types {
-        text/html                             html htm shtml;
-        text/css                              css;
-        text/xml                              xml;
-        image/gif                             gif;
-        image/jpeg                            jpeg jpg;
-        image/png                             png;
-        application/javascript                js;
-        application/octet-stream              bin exe dll;
-        application/octet-stream              deb;
-        application/octet-stream              dmg;
-    }
-
-    default_type application/octet-stream;
-
-    access_log off;
-    sendfile on;
-    keepalive_timeout 65;
-    server_tokens off;
-
-    server {
-        listen %d default_server;
-        server_name localhost example.local test.local _;
-
-        # Match webserv test configuration
-        client_max_body_size 1M;
-
-        # Document root
-        root %s;
-        index index.html index.htm;
-
-        # Root location
-        location / {
-            try_files $uri $uri/ =404;
-        }
-
-        # Upload endpoint - simulate upload behavior
-        location /upload {
-            limit_except POST {
-                return 405 "Method Not Allowed\n";
-            }
-            return 200 "Upload successful\n";
-            add_header Content-Type text/plain;
-        }
-
-        # Directory listing
-        location /public/ {
-            autoindex on;
-            autoindex_exact_size off;
-            autoindex_localtime on;
-        }
-
-        # Redirect test
-        location = /old {
-            return 302 /new-location;
-        }
-
-        # CGI simulation - return expected CGI output
-        location /cgi-bin/ {
-            return 200 "<html><head><title>CGI Test</title></head><body><h1>CGI Script Executed Successfully</h1><p>Request Method: $request_method</p><p>Server Protocol: $server_protocol</p></body></html>";
-            add_header Content-Type text/html;
-        }
-
-        # API endpoint
-        location /api {
-            limit_except GET POST DELETE {
-                return 405 "Method Not Allowed\n";
-            }
-            return 200 "API endpoint\n";
-            add_header Content-Type text/plain;
-        }
-
-        # Error pages
-        error_page 404 /custom_404.html;
-        error_page 500 502 503 504 /custom_50x.html;
-
-        location = /custom_404.html {
-            internal;
-            return 404 "Not Found\n";
-            add_header Content-Type text/plain;
-        }
-
-        location = /custom_50x.html {
-            internal;
-            return 500 "Internal Server Error\n";
-            add_header Content-Type text/plain;
-        }
-    }
+// baselineCase is one scenario exercised against nginx by TestNginxBaseline
+// and, via CompareResponses, against webserv by TestDifferentialConformance.
+type baselineCase struct {
+	name           string
+	method         string
+	uri            string
+	requestBody    string
+	headers        map[string]string
+	expectedStatus int
+	expectedBody   string
+	description    string
 }
-`,
-		n.pidFile,
-		nginxTestPort,
-		filepath.Join(n.workDir, "www"))
 
-	return os.WriteFile(n.configPath, []byte(config), 0644)
+// baselineTestTable is shared by TestNginxBaseline (nginx only) and
+// TestDifferentialConformance (nginx vs. webserv), so every scenario added
+// here becomes both an nginx baseline check and a conformance check.
+var baselineTestTable = []baselineCase{
+	// Static file serving tests
+	{
+		name:           "GetStaticIndex",
+		method:         "GET",
+		uri:            "/",
+		requestBody:    "",
+		expectedStatus: 200,
+		expectedBody:   "html",
+		description:    "NGINX serves static index file with 200 OK",
+	},
+	{
+		name:           "GetStaticFile",
+		method:         "GET",
+		uri:            "/index.html",
+		requestBody:    "",
+		expectedStatus: 200,
+		expectedBody:   "html",
+		description:    "NGINX serves specific static file with 200 OK",
+	},
+	{
+		name:           "GetNotFound",
+		method:         "GET",
+		uri:            "/does/not/exist.txt",
+		requestBody:    "",
+		expectedStatus: 404,
+		expectedBody:   "Not Found",
+		description:    "NGINX returns 404 for non-existent files",
+	},
+
+	// File upload simulation tests
+	{
+		name:           "PostUploadSuccess",
+		method:         "POST",
+		uri:            "/upload",
+		requestBody:    "Test file upload content",
+		headers:        map[string]string{"Content-Type": "application/octet-stream"},
+		expectedStatus: 200,
+		expectedBody:   "Upload successful",
+		description:    "NGINX simulates successful upload with 200 OK",
+	},
+
+	// HTTP method validation tests
+	{
+		name:           "MethodNotAllowedOnUpload",
+		method:         "GET",
+		uri:            "/upload",
+		requestBody:    "",
+		expectedStatus: 405,
+		expectedBody:   "Method Not Allowed",
+		description:    "NGINX returns 405 for GET on upload endpoint",
+	},
+	{
+		name:           "PutMethodNotImplemented",
+		method:         "PUT",
+		uri:            "/",
+		requestBody:    "put data",
+		expectedStatus: 405,
+		expectedBody:   "",
+		description:    "NGINX returns 405 for unsupported PUT method",
+	},
+
+	// CGI simulation tests
+	{
+		name:           "GetCGIScript",
+		method:         "GET",
+		uri:            "/cgi-bin/test.py",
+		requestBody:    "",
+		expectedStatus: 200,
+		expectedBody:   "CGI Script Executed Successfully",
+		description:    "NGINX simulates CGI execution with proper response",
+	},
+	{
+		name:           "PostCGIScript",
+		method:         "POST",
+		uri:            "/cgi-bin/test.py",
+		requestBody:    "name=test&value=data",
+		headers:        map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		expectedStatus: 200,
+		expectedBody:   "CGI Script Executed Successfully",
+		description:    "NGINX handles POST to CGI endpoint",
+	},
+
+	// Directory listing is exercised in its own table by
+	// TestNginxDirectoryListing, which expands it into JSON/sort/limit
+	// cases nginx's stock autoindex can't all satisfy.
+
+	// Redirect tests
+	{
+		name:           "GetRedirect",
+		method:         "GET",
+		uri:            "/old",
+		requestBody:    "",
+		expectedStatus: 302,
+		expectedBody:   "",
+		description:    "NGINX performs 302 redirect as configured",
+	},
+
+	// Virtual host tests
+	{
+		name:           "GetWithHostHeader",
+		method:         "GET",
+		uri:            "/",
+		requestBody:    "",
+		headers:        map[string]string{"Host": "example.local"},
+		expectedStatus: 200,
+		expectedBody:   "",
+		description:    "NGINX handles virtual host headers correctly",
+	},
+
+	// Protocol compliance tests
+	{
+		name:           "GetWithKeepAlive",
+		method:         "GET",
+		uri:            "/",
+		requestBody:    "",
+		headers:        map[string]string{"Connection": "keep-alive"},
+		expectedStatus: 200,
+		expectedBody:   "",
+		description:    "NGINX supports HTTP/1.1 keep-alive connections",
+	},
 }
 
-// Start starts the nginx server
-func (n *NginxComparison) Start() error {
-	if !n.IsAvailable() {
-		return fmt.Errorf("nginx binary not found")
+// TestNginxBaseline tests NGINX baseline behavior for HTTP server functionality
+//
+// This table-driven test establishes the expected behavior baseline that our webserv
+// implementation should match. By testing against NGINX, we validate:
+// 1. Our test scenarios are realistic and achievable
+// 2. Expected status codes and responses are correct
+// 3. HTTP/1.1 protocol compliance expectations
+func TestNginxBaseline(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for baseline testing")
 	}
-
-	if err := n.GenerateConfig(); err != nil {
-		return fmt.Errorf("failed to generate nginx config: %w", err)
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
 	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
 
-	// Get absolute path for configuration
-	absConfigPath, err := filepath.Abs(n.configPath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute config path: %w", err)
-	}
+	// Execute each test case to establish NGINX baseline behavior
+	for _, tc := range baselineTestTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
 
-	// Test the configuration first
-	testCmd := exec.Command(n.nginxPath, "-t", "-c", absConfigPath)
-	if output, err := testCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("nginx config test failed: %w\nOutput: %s", err, string(output))
-	}
+			t.Logf("Testing NGINX baseline: %s", tc.description)
 
-	// Start nginx
-	cmd := exec.Command(n.nginxPath, "-c", absConfigPath)
+			statusCode, statusText, responseBody, req := sendNginxRequest(t, nginx, client, tc.method, tc.uri, tc.requestBody, tc.headers)
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start nginx: %w", err)
-	}
+			t.Logf("NGINX Response: %d %s", statusCode, statusText)
+			if len(responseBody) > 0 && len(responseBody) < 200 {
+				t.Logf("Response body: %q", responseBody)
+			}
 
-	// Give nginx a moment to start
-	time.Sleep(500 * time.Millisecond)
+			// Assert status code matches expectation
+			if statusCode != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tc.expectedStatus, statusCode, statusText)
+				t.Logf("reproduce: %s", curlForRequest(req, []byte(tc.requestBody)))
+			}
 
-	// Check if it's responding
-	for i := 0; i < 20; i++ {
-		if n.isResponding() {
-			n.running = true
-			fmt.Printf("NGINX started successfully on port %d\n", nginxTestPort)
-			return nil
-		}
-		time.Sleep(100 * time.Millisecond)
+			// Assert response body contains expected content (if specified)
+			if tc.expectedBody != "" && !strings.Contains(responseBody, tc.expectedBody) {
+				t.Errorf("Expected response body to contain %q, got: %s", tc.expectedBody, responseBody)
+				t.Logf("reproduce: %s", curlForRequest(req, []byte(tc.requestBody)))
+			}
+		})
 	}
-
-	return fmt.Errorf("nginx failed to start responding within timeout")
 }
 
-// Stop stops the nginx server
-func (n *NginxComparison) Stop() error {
-	if !n.running {
-		return nil
-	}
+// updateAutoindexGolden regenerates the fixtures TestNginxAutoindexBaseline
+// compares against instead of comparing against them.
+var updateAutoindexGolden = flag.Bool("update-autoindex-golden", false, "regenerate docs/integration/testdata/golden/ autoindex fixtures")
 
-	// Try graceful shutdown first
-	if err := exec.Command(n.nginxPath, "-c", n.configPath, "-s", "quit").Run(); err != nil {
-		// Force stop if graceful shutdown fails
-		exec.Command(n.nginxPath, "-c", n.configPath, "-s", "stop").Run()
-	}
+// autoindexVolatileColumn masks the mtime/size columns nginx's autoindex
+// module prints after each entry, which vary with the filesystem, so the
+// golden comparison only pins the structural HTML (links and ordering).
+var autoindexVolatileColumn = regexp.MustCompile(`</a>\s+\d{2}-\w{3}-\d{4} \d{2}:\d{2}\s+\S+`)
+
+func maskAutoindexVolatileColumns(body string) string {
+	return autoindexVolatileColumn.ReplaceAllString(body, "</a> {{MTIME}} {{SIZE}}")
+}
 
-	n.running = false
-	fmt.Println("NGINX stopped")
+// assertAutoindexGolden compares body against
+// testdata/golden/<name>.golden, writing it instead when
+// -update-autoindex-golden is passed.
+func assertAutoindexGolden(t *testing.T, name, body string) {
+	t.Helper()
 
-	// Clean up files
-	os.Remove(n.configPath)
-	os.Remove(n.pidFile)
+	path := filepath.Join("testdata", "golden", name+".golden")
 
-	return nil
-}
+	if *updateAutoindexGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("failed to write golden fixture: %v", err)
+		}
+		t.Logf("updated golden fixture %s", path)
+		return
+	}
 
-// isResponding checks if nginx is responding to requests
-func (n *NginxComparison) isResponding() bool {
-	client := &http.Client{Timeout: 1 * time.Second}
-	resp, err := client.Get(fmt.Sprintf("http://%s:%d/", nginxTestHost, nginxTestPort))
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden fixture %s does not exist; run with -update-autoindex-golden to create it", path)
+	}
 	if err != nil {
-		return false
+		t.Fatalf("failed to read golden fixture: %v", err)
 	}
-	resp.Body.Close()
-	return true
-}
 
-// GetURL returns nginx URL for given path
-func (n *NginxComparison) GetURL(path string) string {
-	return fmt.Sprintf("http://%s:%d%s", nginxTestHost, nginxTestPort, path)
+	if string(want) != body {
+		t.Errorf("autoindex listing for %s diverged from golden fixture %s:\nwant: %q\ngot:  %q", name, path, want, body)
+	}
 }
 
-// TestMain manages the NGINX lifecycle for all tests
-func TestMain(m *testing.M) {
-	// Setup: Start NGINX
-	if err := setupNginxEnvironment(); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to setup NGINX environment: %v\n", err)
-		os.Exit(1)
+// TestNginxAutoindexBaseline pins nginx's stock autoindex behavior: it has
+// no concept of the Caddy-style ?sort=/&order= query parameters a richer
+// webserv autoindex would honor, so it lists entries alphabetically
+// regardless of the query string. This gives a concrete baseline for the
+// webserv behavior to diverge from on purpose, rather than by accident.
+func TestNginxAutoindexBaseline(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for autoindex testing")
 	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
 
-	// Run all tests
-	exitCode := m.Run()
+	t.Run("IgnoresUnknownSortQueryParams", func(t *testing.T) {
+		plain, err := client.Get(nginx.GetURL("/public/"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer plain.Body.Close()
+		plainBody, _ := io.ReadAll(plain.Body)
 
-	// Teardown: Stop NGINX
-	teardownNginxEnvironment()
+		sorted, err := client.Get(nginx.GetURL("/public/?sort=size&order=desc"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer sorted.Body.Close()
+		sortedBody, _ := io.ReadAll(sorted.Body)
 
-	os.Exit(exitCode)
-}
+		if sorted.StatusCode != 200 {
+			t.Fatalf("expected 200, got %d", sorted.StatusCode)
+		}
+		if string(plainBody) != string(sortedBody) {
+			t.Errorf("expected nginx's autoindex output to be identical regardless of ?sort=/&order=, got a difference")
+		}
+	})
 
-// setupNginxEnvironment starts NGINX for testing
-func setupNginxEnvironment() error {
-	nginxInstance = NewNginxComparison("test-server")
+	t.Run("Golden", func(t *testing.T) {
+		resp, err := client.Get(nginx.GetURL("/public/"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
 
-	if !nginxInstance.IsAvailable() {
-		return fmt.Errorf("nginx not available for testing")
-	}
+		assertAutoindexGolden(t, "nginx_public_listing", maskAutoindexVolatileColumns(string(body)))
+	})
+}
 
-	if err := nginxInstance.Start(); err != nil {
-		return fmt.Errorf("failed to start nginx: %w", err)
+// TestNginxContentEncodingBaseline pins nginx's gzip negotiation (enabled
+// in writeNginxConfig) as the baseline a selfserv response-encoding layer
+// would need to match: Accept-Encoding: gzip gets back a
+// Content-Encoding: gzip body whose decompressed bytes equal the
+// uncompressed response, plus Vary: Accept-Encoding so caches key on it.
+func TestNginxContentEncodingBaseline(t *testing.T) {
+	nginx := NewNginxComparison(t.TempDir())
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for content-encoding testing")
 	}
-
-	nginxClient = &http.Client{
-		Timeout: requestTimeout,
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
 	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
 
-	return nil
-}
-
-// teardownNginxEnvironment cleans up NGINX
-func teardownNginxEnvironment() {
-	if nginxInstance != nil {
-		nginxInstance.Stop()
+	plainResp, err := client.Get(nginx.GetURL("/"))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer plainResp.Body.Close()
+	plainBody, err := io.ReadAll(plainResp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
 	}
-}
-
-// sendNginxRequest is a test helper for sending requests to NGINX
-func sendNginxRequest(t *testing.T, method, uri, body string, headers map[string]string) (statusCode int, statusText, responseBody string) {
-	t.Helper()
 
-	var bodyReader io.Reader
-	if body != "" {
-		bodyReader = strings.NewReader(body)
+	req, err := http.NewRequest("GET", nginx.GetURL("/"), nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
 	}
+	req.Header.Set("Accept-Encoding", "gzip")
 
-	url := nginxInstance.GetURL(uri)
-	req, err := http.NewRequest(method, url, bodyReader)
+	resp, err := client.Do(req)
 	if err != nil {
-		t.Fatalf("Failed to create request: %v", err)
+		t.Fatalf("failed to send request: %v", err)
 	}
+	defer resp.Body.Close()
 
-	// Add custom headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	if enc := resp.Header.Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("expected Content-Encoding: gzip, got %q", enc)
+	}
+	if vary := resp.Header.Get("Vary"); !strings.Contains(vary, "Accept-Encoding") {
+		t.Errorf("expected Vary to mention Accept-Encoding, got %q", vary)
 	}
 
-	// Send request using nginx client
-	resp, err := nginxClient.Do(req)
+	gzReader, err := gzip.NewReader(resp.Body)
 	if err != nil {
-		t.Fatalf("Failed to send request: %v", err)
+		t.Fatalf("failed to construct gzip reader: %v", err)
 	}
-	defer resp.Body.Close()
+	defer gzReader.Close()
 
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
+	decompressed, err := io.ReadAll(gzReader)
 	if err != nil {
-		t.Fatalf("Failed to read response body: %v", err)
+		t.Fatalf("failed to decompress response body: %v", err)
 	}
 
-	return resp.StatusCode, resp.Status, string(bodyBytes)
+	if string(decompressed) != string(plainBody) {
+		t.Errorf("decompressed gzip body does not match the uncompressed response")
+	}
 }
 
-// TestNginxBaseline tests NGINX baseline behavior for HTTP server functionality
-//
-// This table-driven test establishes the expected behavior baseline that our webserv
-// implementation should match. By testing against NGINX, we validate:
-// 1. Our test scenarios are realistic and achievable
-// 2. Expected status codes and responses are correct
-// 3. HTTP/1.1 protocol compliance expectations
-func TestNginxBaseline(t *testing.T) {
-	type testCase struct {
-		name           string
-		method         string
-		uri            string
-		requestBody    string
-		headers        map[string]string
-		expectedStatus int
-		expectedBody   string
-		description    string
-	}
-
-	testTable := []testCase{
-		// Static file serving tests
-		{
-			name:           "GetStaticIndex",
-			method:         "GET",
-			uri:            "/",
-			requestBody:    "",
-			expectedStatus: 200,
-			expectedBody:   "html",
-			description:    "NGINX serves static index file with 200 OK",
-		},
-		{
-			name:           "GetStaticFile",
-			method:         "GET",
-			uri:            "/index.html",
-			requestBody:    "",
-			expectedStatus: 200,
-			expectedBody:   "html",
-			description:    "NGINX serves specific static file with 200 OK",
-		},
-		{
-			name:           "GetNotFound",
-			method:         "GET",
-			uri:            "/does/not/exist.txt",
-			requestBody:    "",
-			expectedStatus: 404,
-			expectedBody:   "Not Found",
-			description:    "NGINX returns 404 for non-existent files",
-		},
-
-		// File upload simulation tests
-		{
-			name:           "PostUploadSuccess",
-			method:         "POST",
-			uri:            "/upload",
-			requestBody:    "Test file upload content",
-			headers:        map[string]string{"Content-Type": "application/octet-stream"},
-			expectedStatus: 200,
-			expectedBody:   "Upload successful",
-			description:    "NGINX simulates successful upload with 200 OK",
-		},
-
-		// HTTP method validation tests
-		{
-			name:           "MethodNotAllowedOnUpload",
-			method:         "GET",
-			uri:            "/upload",
-			requestBody:    "",
-			expectedStatus: 405,
-			expectedBody:   "Method Not Allowed",
-			description:    "NGINX returns 405 for GET on upload endpoint",
-		},
-		{
-			name:           "PutMethodNotImplemented",
-			method:         "PUT",
-			uri:            "/",
-			requestBody:    "put data",
-			expectedStatus: 405,
-			expectedBody:   "",
-			description:    "NGINX returns 405 for unsupported PUT method",
-		},
+// TestNginxCGIExecution exercises the fixture scripts in
+// test-server/cgi-bin/ through nginx's real FastCGI client and fcgiwrap at
+// /fcgi-bin/, rather than the static /cgi-bin/ "CGI Test Successful"
+// simulation writeNginxConfig falls back to when fcgiwrap isn't installed.
+func TestNginxCGIExecution(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for CGI execution testing")
+	}
+	if !nginx.EnableFastCGI() {
+		t.Skip("fcgiwrap not found; real CGI execution requires it")
+	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
 
-		// CGI simulation tests
-		{
-			name:           "GetCGIScript",
-			method:         "GET",
-			uri:            "/cgi-bin/test.py",
-			requestBody:    "",
-			expectedStatus: 200,
-			expectedBody:   "CGI Script Executed Successfully",
-			description:    "NGINX simulates CGI execution with proper response",
-		},
-		{
-			name:           "PostCGIScript",
-			method:         "POST",
-			uri:            "/cgi-bin/test.py",
-			requestBody:    "name=test&value=data",
-			headers:        map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
-			expectedStatus: 200,
-			expectedBody:   "CGI Script Executed Successfully",
-			description:    "NGINX handles POST to CGI endpoint",
-		},
+	t.Run("PostBodyIsPassedThrough", func(t *testing.T) {
+		resp, err := client.Post(nginx.GetURL("/fcgi-bin/echo.py"), "application/x-www-form-urlencoded", strings.NewReader("name=test&value=data"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
 
-		// Directory listing tests
-		{
-			name:           "GetDirectoryListing",
-			method:         "GET",
-			uri:            "/public/",
-			requestBody:    "",
-			expectedStatus: 200,
-			expectedBody:   "", // Directory listing format varies
-			description:    "NGINX provides directory listing when enabled",
-		},
+		if resp.StatusCode != 200 {
+			t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+		}
+		if !strings.Contains(string(body), "REQUEST_METHOD=POST") {
+			t.Errorf("expected REQUEST_METHOD=POST in response, got: %s", body)
+		}
+		if !strings.Contains(string(body), "BODY=name=test&value=data") {
+			t.Errorf("expected the POST body to be echoed back, got: %s", body)
+		}
+	})
 
-		// Redirect tests
-		{
-			name:           "GetRedirect",
-			method:         "GET",
-			uri:            "/old",
-			requestBody:    "",
-			expectedStatus: 302,
-			expectedBody:   "",
-			description:    "NGINX performs 302 redirect as configured",
-		},
+	t.Run("NonZeroExitMapsTo502", func(t *testing.T) {
+		resp, err := client.Get(nginx.GetURL("/fcgi-bin/fail.py"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
 
-		// Virtual host tests
-		{
-			name:           "GetWithHostHeader",
-			method:         "GET",
-			uri:            "/",
-			requestBody:    "",
-			headers:        map[string]string{"Host": "example.local"},
-			expectedStatus: 200,
-			expectedBody:   "",
-			description:    "NGINX handles virtual host headers correctly",
-		},
+		if resp.StatusCode != 502 {
+			t.Errorf("expected 502 for a script that exits non-zero without a response, got %d", resp.StatusCode)
+		}
+	})
 
-		// Protocol compliance tests
-		{
-			name:           "GetWithKeepAlive",
-			method:         "GET",
-			uri:            "/",
-			requestBody:    "",
-			headers:        map[string]string{"Connection": "keep-alive"},
-			expectedStatus: 200,
-			expectedBody:   "",
-			description:    "NGINX supports HTTP/1.1 keep-alive connections",
-		},
-	}
+	t.Run("LocationHeaderProducesRedirect", func(t *testing.T) {
+		client := &http.Client{
+			Timeout: requestTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
 
-	// Execute each test case to establish NGINX baseline behavior
-	for _, tc := range testTable {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
+		resp, err := client.Get(nginx.GetURL("/fcgi-bin/redirect.py"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
 
-			t.Logf("Testing NGINX baseline: %s", tc.description)
+		if resp.StatusCode != 302 {
+			t.Errorf("expected nginx to turn a bare CGI Location header into a 302, got %d", resp.StatusCode)
+		}
+		if loc := resp.Header.Get("Location"); loc != "/new-location" {
+			t.Errorf("expected Location: /new-location, got %q", loc)
+		}
+	})
 
-			statusCode, statusText, responseBody := sendNginxRequest(t, tc.method, tc.uri, tc.requestBody, tc.headers)
+	t.Run("ScriptTimeoutMapsTo504", func(t *testing.T) {
+		resp, err := client.Get(nginx.GetURL("/fcgi-bin/slow.py"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
 
-			t.Logf("NGINX Response: %d %s", statusCode, statusText)
-			if len(responseBody) > 0 && len(responseBody) < 200 {
-				t.Logf("Response body: %q", responseBody)
-			}
+		if resp.StatusCode != 504 {
+			t.Errorf("expected 504 once fastcgi_read_timeout elapses, got %d", resp.StatusCode)
+		}
+	})
+}
 
-			// Assert status code matches expectation
-			if statusCode != tc.expectedStatus {
-				t.Errorf("Expected status %d, got %d (%s)", tc.expectedStatus, statusCode, statusText)
-			}
+// multipartTestCase is one scenario exercised against nginx by
+// TestNginxMultipartUpload.
+type multipartTestCase struct {
+	name           string
+	fieldName      string
+	fileName       string
+	fileContent    string
+	expectedStatus int
+	expectedBody   string
+	description    string
+}
 
-			// Assert response body contains expected content (if specified)
-			if tc.expectedBody != "" && !strings.Contains(responseBody, tc.expectedBody) {
-				t.Errorf("Expected response body to contain %q, got: %s", tc.expectedBody, responseBody)
-			}
-		})
-	}
+// multipartTestTable is shared by TestNginxMultipartUpload (nginx only) and
+// TestDifferentialConformance (nginx vs. webserv).
+var multipartTestTable = []multipartTestCase{
+	{
+		name:           "MultipartUploadToUploadEndpoint",
+		fieldName:      "file",
+		fileName:       "test.txt",
+		fileContent:    "This is test file content for multipart upload",
+		expectedStatus: 200,
+		expectedBody:   "Upload successful",
+		description:    "NGINX handles multipart upload to configured endpoint",
+	},
 }
 
 // TestNginxMultipartUpload tests NGINX multipart handling baseline
 func TestNginxMultipartUpload(t *testing.T) {
-	type multipartTestCase struct {
-		name           string
-		fieldName      string
-		fileName       string
-		fileContent    string
-		expectedStatus int
-		expectedBody   string
-		description    string
+	nginx := NewNginxComparison(t.TempDir())
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for multipart testing")
 	}
-
-	testTable := []multipartTestCase{
-		{
-			name:           "MultipartUploadToUploadEndpoint",
-			fieldName:      "file",
-			fileName:       "test.txt",
-			fileContent:    "This is test file content for multipart upload",
-			expectedStatus: 200,
-			expectedBody:   "Upload successful",
-			description:    "NGINX handles multipart upload to configured endpoint",
-		},
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
 	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
 
-	for _, tc := range testTable {
+	for _, tc := range multipartTestTable {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
@@ -576,14 +574,19 @@ func TestNginxMultipartUpload(t *testing.T) {
 				t.Fatalf("Failed to close multipart writer: %v", err)
 			}
 
+			// Snapshot the encoded body before building the request: once
+			// client.Do sends it, the reader is drained, but curlForRequest
+			// needs the original bytes to reproduce it afterward.
+			requestBody := append([]byte(nil), buf.Bytes()...)
+
 			// Send multipart request to NGINX
-			req, err := http.NewRequest("POST", nginxInstance.GetURL("/upload"), &buf)
+			req, err := http.NewRequest("POST", nginx.GetURL("/upload"), bytes.NewReader(requestBody))
 			if err != nil {
 				t.Fatalf("Failed to create request: %v", err)
 			}
 			req.Header.Set("Content-Type", writer.FormDataContentType())
 
-			resp, err := nginxClient.Do(req)
+			resp, err := client.Do(req)
 			if err != nil {
 				t.Fatalf("Failed to send request: %v", err)
 			}
@@ -597,11 +600,309 @@ func TestNginxMultipartUpload(t *testing.T) {
 			// Assert status code
 			if resp.StatusCode != tc.expectedStatus {
 				t.Errorf("Expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+				t.Logf("reproduce: %s", curlForRequest(req, requestBody))
 			}
 
 			// Assert response body if specified
 			if tc.expectedBody != "" && !strings.Contains(string(responseBody), tc.expectedBody) {
 				t.Errorf("Expected response body to contain %q, got: %s", tc.expectedBody, responseBody)
+				t.Logf("reproduce: %s", curlForRequest(req, requestBody))
+			}
+		})
+	}
+}
+
+// TestNginxVersionMatrix runs a handful of the TestNginxBaseline scenarios
+// against every pinned image in nginxVersionMatrix, each in its own
+// container started concurrently, so a behavior change between nginx
+// releases shows up without needing a separate test per version.
+func TestNginxVersionMatrix(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping nginx version matrix in short mode")
+	}
+
+	sampleCases := []baselineCase{}
+	for _, name := range []string{"GetStaticIndex", "GetNotFound", "PostUploadSuccess"} {
+		for _, tc := range baselineTestTable {
+			if tc.name == name {
+				sampleCases = append(sampleCases, tc)
+			}
+		}
+	}
+
+	for _, image := range nginxVersionMatrix {
+		image := image
+		t.Run(image, func(t *testing.T) {
+			t.Parallel()
+
+			instance := NewNginxComparisonWithImage(t.TempDir(), image)
+			if !instance.IsAvailable() {
+				t.Skip("Docker not available for version matrix testing")
+			}
+			if err := instance.Start(); err != nil {
+				t.Fatalf("failed to start %s: %v", image, err)
+			}
+			defer instance.Stop()
+
+			client := &http.Client{Timeout: requestTimeout}
+			for _, tc := range sampleCases {
+				tc := tc
+				t.Run(tc.name, func(t *testing.T) {
+					var bodyReader io.Reader
+					if tc.requestBody != "" {
+						bodyReader = strings.NewReader(tc.requestBody)
+					}
+
+					req, err := http.NewRequest(tc.method, instance.GetURL(tc.uri), bodyReader)
+					if err != nil {
+						t.Fatalf("failed to build request: %v", err)
+					}
+					for key, value := range tc.headers {
+						req.Header.Set(key, value)
+					}
+
+					resp, err := client.Do(req)
+					if err != nil {
+						t.Fatalf("failed to send request: %v", err)
+					}
+					defer resp.Body.Close()
+
+					if resp.StatusCode != tc.expectedStatus {
+						t.Errorf("%s: expected status %d, got %d", image, tc.expectedStatus, resp.StatusCode)
+					}
+				})
+			}
+		})
+	}
+}
+
+// directoryListingCase is one scenario TestNginxDirectoryListing exercises
+// against /public/, expanding the old single GetDirectoryListing baseline
+// case into JSON/sort/limit variants.
+type directoryListingCase struct {
+	name           string
+	query          string
+	acceptJSON     bool
+	skipNginx      bool
+	skipReason     string
+	expectedStatus int
+	description    string
+}
+
+var directoryListingTestTable = []directoryListingCase{
+	{
+		name:           "HTMLListing",
+		expectedStatus: 200,
+		description:    "NGINX autoindex renders an HTML directory listing",
+	},
+	{
+		name:           "SortedByName",
+		query:          "?sort=name&order=asc",
+		expectedStatus: 200,
+		description:    "Listing accepts sort/order query params (nginx's autoindex ignores them but must not error)",
+	},
+	{
+		name:           "LimitedListing",
+		query:          "?limit=1",
+		expectedStatus: 200,
+		description:    "Listing accepts a limit query param (nginx's autoindex ignores it but must not error)",
+	},
+	{
+		name:           "JSONListing",
+		acceptJSON:     true,
+		skipNginx:      true,
+		skipReason:     "stock nginx autoindex has no JSON mode; staticfiles.Browse (modeling webserv's future /public/ handler) is what implements content negotiation - see TestStaticfilesBrowseListing",
+		expectedStatus: 200,
+		description:    "Accept: application/json receives a JSON array of entries instead of HTML",
+	},
+}
+
+// TestNginxDirectoryListing exercises /public/ with query-string sorting
+// and JSON content negotiation. JSON cases are skipped against nginx,
+// which has no such mode.
+func TestNginxDirectoryListing(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for directory listing testing")
+	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
+
+	for _, tc := range directoryListingTestTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.skipNginx {
+				t.Skip(tc.skipReason)
+			}
+
+			t.Logf("Testing directory listing: %s", tc.description)
+
+			headers := map[string]string{}
+			if tc.acceptJSON {
+				headers["Accept"] = "application/json"
+			}
+
+			statusCode, statusText, _, req := sendNginxRequest(t, nginx, client, "GET", "/public/"+tc.query, "", headers)
+
+			t.Logf("NGINX Response: %d %s", statusCode, statusText)
+			if statusCode != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tc.expectedStatus, statusCode, statusText)
+				t.Logf("reproduce: %s", curlForRequest(req, nil))
+			}
+		})
+	}
+}
+
+// TLS coverage lives in nginx_comparison_test.go rather than here:
+// TestProtocolComplianceComparison's "TLS parity" subtest covers the
+// canonical provider's always-on HTTPS listener (handshake success, ALPN,
+// HSTS, and rejecting a plaintext request on the TLS port), and
+// TestNginxTLSBaseline covers SNI-routed certificate selection.
+
+// TestStaticfilesBrowseListing exercises staticfiles.Browse directly
+// against a fixture directory, since no webserv binary exists in this tree
+// to wire it into. It pins down the contract TestNginxDirectoryListing's
+// skipped JSON case describes: sorted output, a respected limit, and JSON
+// content negotiation.
+func TestStaticfilesBrowseListing(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to write fixture %s: %v", name, err)
+		}
+	}
+
+	browse := staticfiles.NewBrowse()
+
+	decodeListing := func(t *testing.T, query string) []staticfiles.FileInfo {
+		t.Helper()
+
+		req := httptest.NewRequest("GET", "/public/"+query, nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+
+		if err := browse.ServeListing(rec, req, dir, "/public/"); err != nil {
+			t.Fatalf("ServeListing failed: %v", err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected JSON content type, got %q", ct)
+		}
+
+		var items []staticfiles.FileInfo
+		if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+			t.Fatalf("failed to decode JSON listing: %v", err)
+		}
+		return items
+	}
+
+	t.Run("JSONContentNegotiation", func(t *testing.T) {
+		items := decodeListing(t, "")
+		if len(items) != 3 {
+			t.Errorf("expected 3 items, got %d", len(items))
+		}
+	})
+
+	t.Run("SortedByNameAscending", func(t *testing.T) {
+		items := decodeListing(t, "?sort=name&order=asc")
+		for i := 1; i < len(items); i++ {
+			if items[i-1].Name > items[i].Name {
+				t.Errorf("expected ascending name order, got %v", items)
+				break
+			}
+		}
+	})
+
+	t.Run("SortedByNameDescending", func(t *testing.T) {
+		items := decodeListing(t, "?sort=name&order=desc")
+		for i := 1; i < len(items); i++ {
+			if items[i-1].Name < items[i].Name {
+				t.Errorf("expected descending name order, got %v", items)
+				break
+			}
+		}
+	})
+
+	t.Run("RespectsLimit", func(t *testing.T) {
+		items := decodeListing(t, "?limit=1")
+		if len(items) != 1 {
+			t.Errorf("expected limit=1 to yield 1 item, got %d", len(items))
+		}
+	})
+
+	t.Run("LimitMessageReportsPreTruncationTotal", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/public/?limit=1", nil)
+		rec := httptest.NewRecorder()
+
+		if err := browse.ServeListing(rec, req, dir, "/public/"); err != nil {
+			t.Fatalf("ServeListing failed: %v", err)
+		}
+
+		body := rec.Body.String()
+		if !strings.Contains(body, "Showing first 1 of 3 entries.") {
+			t.Errorf("expected the limit message to report the pre-truncation total of 3 entries, got: %s", body)
+		}
+	})
+}
+
+// TestDifferentialConformance drives every TestNginxBaseline and
+// TestNginxMultipartUpload scenario through nginx_comparison_test.go's
+// CompareResponses, turning the one-sided nginx baseline log into a
+// pass/fail conformance check against webserv.
+func TestDifferentialConformance(t *testing.T) {
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for differential conformance testing")
+	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+
+	for _, tc := range baselineTestTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			comparison, err := CompareResponses(getTestURL(tc.uri), nginx.GetURL(tc.uri), tc.method, []byte(tc.requestBody), tc.headers)
+			if err != nil {
+				t.Fatalf("comparison failed: %v", err)
+			}
+			if !comparison.StatusMatch || !comparison.BodyMatch || len(comparison.HeaderOnlyInWebserv) > 0 || len(comparison.HeaderOnlyInNginx) > 0 || len(comparison.HeaderValueDiffs) > 0 {
+				t.Errorf("webserv and nginx diverge for %s:\n%s", tc.description, comparison.Diff())
+			}
+		})
+	}
+
+	for _, tc := range multipartTestTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+			fileWriter, err := writer.CreateFormFile(tc.fieldName, tc.fileName)
+			if err != nil {
+				t.Fatalf("failed to create form file: %v", err)
+			}
+			if _, err := fileWriter.Write([]byte(tc.fileContent)); err != nil {
+				t.Fatalf("failed to write file content: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close multipart writer: %v", err)
+			}
+
+			headers := map[string]string{"Content-Type": writer.FormDataContentType()}
+			comparison, err := CompareResponses(getTestURL("/upload"), nginx.GetURL("/upload"), "POST", buf.Bytes(), headers)
+			if err != nil {
+				t.Fatalf("comparison failed: %v", err)
+			}
+			if !comparison.StatusMatch || !comparison.BodyMatch || len(comparison.HeaderOnlyInWebserv) > 0 || len(comparison.HeaderOnlyInNginx) > 0 || len(comparison.HeaderValueDiffs) > 0 {
+				t.Errorf("webserv and nginx diverge for %s:\n%s", tc.description, comparison.Diff())
 			}
 		})
 	}
@@ -609,6 +910,16 @@ func TestNginxMultipartUpload(t *testing.T) {
 
 // TestNginxProtocolCompliance tests NGINX HTTP/1.1 protocol compliance baseline
 func TestNginxProtocolCompliance(t *testing.T) {
+	nginx := NewNginxComparison(t.TempDir())
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for protocol compliance testing")
+	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
+
 	type protocolTestCase struct {
 		name        string
 		testFunc    func(t *testing.T)
@@ -620,7 +931,11 @@ func TestNginxProtocolCompliance(t *testing.T) {
 			name:        "HTTP11Protocol",
 			description: "NGINX responds with HTTP/1.1 protocol",
 			testFunc: func(t *testing.T) {
-				resp, err := nginxClient.Get(nginxInstance.GetURL("/"))
+				req, err := http.NewRequest("GET", nginx.GetURL("/"), nil)
+				if err != nil {
+					t.Fatalf("Failed to build request: %v", err)
+				}
+				resp, err := client.Do(req)
 				if err != nil {
 					t.Fatalf("Failed to send request: %v", err)
 				}
@@ -630,6 +945,7 @@ func TestNginxProtocolCompliance(t *testing.T) {
 
 				if resp.Proto != "HTTP/1.1" {
 					t.Errorf("Expected HTTP/1.1, got %s", resp.Proto)
+					t.Logf("reproduce: %s", curlForRequest(req, nil))
 				}
 			},
 		},
@@ -637,7 +953,7 @@ func TestNginxProtocolCompliance(t *testing.T) {
 			name:        "KeepAliveConnections",
 			description: "NGINX supports keep-alive connections",
 			testFunc: func(t *testing.T) {
-				conn, err := net.Dial("tcp", fmt.Sprintf("%s:%d", nginxTestHost, nginxTestPort))
+				conn, err := net.Dial("tcp", nginx.Addr())
 				if err != nil {
 					t.Fatalf("Failed to connect: %v", err)
 				}
@@ -645,7 +961,7 @@ func TestNginxProtocolCompliance(t *testing.T) {
 
 				// Send multiple requests on same connection
 				for i := 0; i < 3; i++ {
-					request := fmt.Sprintf("GET /?req=%d HTTP/1.1\r\nHost: %s\r\nConnection: keep-alive\r\n\r\n", i, nginxTestHost)
+					request := fmt.Sprintf("GET /?req=%d HTTP/1.1\r\nHost: %s\r\nConnection: keep-alive\r\n\r\n", i, nginx.Host())
 					_, err := conn.Write([]byte(request))
 					if err != nil {
 						t.Fatalf("Failed to write request %d: %v", i, err)
@@ -668,7 +984,11 @@ func TestNginxProtocolCompliance(t *testing.T) {
 			name:        "ResponseHeaders",
 			description: "NGINX includes standard HTTP headers",
 			testFunc: func(t *testing.T) {
-				resp, err := nginxClient.Get(nginxInstance.GetURL("/"))
+				req, err := http.NewRequest("GET", nginx.GetURL("/"), nil)
+				if err != nil {
+					t.Fatalf("Failed to build request: %v", err)
+				}
+				resp, err := client.Do(req)
 				if err != nil {
 					t.Fatalf("Failed to send request: %v", err)
 				}
@@ -681,6 +1001,7 @@ func TestNginxProtocolCompliance(t *testing.T) {
 					t.Logf("NGINX %s header: %s", header, value)
 					if value == "" {
 						t.Errorf("NGINX should include %s header", header)
+						t.Logf("reproduce: %s", curlForRequest(req, nil))
 					}
 				}
 
@@ -689,6 +1010,7 @@ func TestNginxProtocolCompliance(t *testing.T) {
 				t.Logf("NGINX Date header: %s", dateHeader)
 				if dateHeader == "" {
 					t.Error("NGINX should include Date header")
+					t.Logf("reproduce: %s", curlForRequest(req, nil))
 				}
 			},
 		},
@@ -704,78 +1026,196 @@ func TestNginxProtocolCompliance(t *testing.T) {
 	}
 }
 
-// TestNginxPerformanceBaseline establishes performance baseline with NGINX
+// latencyStats summarizes a slice of request durations: the percentiles an
+// SLO-style threshold should gate on (we fail on p99, never on the mean -
+// see TestNginxPerformanceBaseline) plus the max for spotting outliers.
+type latencyStats struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	P999  time.Duration `json:"p999"`
+	Max   time.Duration `json:"max"`
+}
+
+// computeLatencyStats sorts samples in place and takes each percentile p as
+// the sample at index floor(p*len(samples)), clamped to the last sample.
+// samples must be non-empty.
+func computeLatencyStats(samples []time.Duration) latencyStats {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var sum time.Duration
+	for _, s := range samples {
+		sum += s
+	}
+
+	percentile := func(p float64) time.Duration {
+		idx := int(p * float64(len(samples)))
+		if idx >= len(samples) {
+			idx = len(samples) - 1
+		}
+		return samples[idx]
+	}
+
+	return latencyStats{
+		Count: len(samples),
+		Min:   samples[0],
+		Mean:  sum / time.Duration(len(samples)),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		P999:  percentile(0.999),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+func (s latencyStats) logTo(t *testing.T) {
+	t.Logf("  Latency: min=%v mean=%v p50=%v p90=%v p99=%v p999=%v max=%v",
+		s.Min, s.Mean, s.P50, s.P90, s.P99, s.P999, s.Max)
+}
+
+// perfCaseReport is one performance case's outcome, collected across the
+// whole test and written to -perf.json (when set) for trend tracking
+// across commits.
+type perfCaseReport struct {
+	Name              string        `json:"name"`
+	NumRequests       int           `json:"num_requests"`
+	NumConcurrent     int           `json:"num_concurrent"`
+	Duration          time.Duration `json:"duration"`
+	SuccessRate       float64       `json:"success_rate"`
+	RequestsPerSecond float64       `json:"requests_per_sec"`
+	Latency           latencyStats  `json:"latency"`
+}
+
+// writePerfJSON writes reports to -perf.json if the flag was set; it is a
+// no-op otherwise.
+func writePerfJSON(t *testing.T, reports []perfCaseReport) {
+	if *perfJSONPath == "" {
+		return
+	}
+	f, err := os.Create(*perfJSONPath)
+	if err != nil {
+		t.Errorf("failed to create -perf.json file %s: %v", *perfJSONPath, err)
+		return
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(reports); err != nil {
+		t.Errorf("failed to write -perf.json: %v", err)
+	}
+}
+
+// runPerfRequests fires numRequests GETs against nginx across a pool of
+// numConcurrent workers, recording one latency sample per request, and
+// returns the samples alongside the errors of any requests that failed or
+// returned a 4xx/5xx status.
+func runPerfRequests(nginx *NginxComparison, client *http.Client, numRequests, numConcurrent int) (latencies []time.Duration, errs []error) {
+	type result struct {
+		latency time.Duration
+		err     error
+	}
+	results := make(chan result, numRequests)
+	semaphore := make(chan struct{}, numConcurrent)
+
+	for i := 0; i < numRequests; i++ {
+		go func(id int) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			start := time.Now()
+			resp, err := client.Get(nginx.GetURL(fmt.Sprintf("/?req=%d", id)))
+			elapsed := time.Since(start)
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode >= 400 {
+				results <- result{latency: elapsed, err: fmt.Errorf("request %d returned error: %d", id, resp.StatusCode)}
+				return
+			}
+			results <- result{latency: elapsed}
+		}(i)
+	}
+
+	for i := 0; i < numRequests; i++ {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, r.err)
+		}
+		if r.latency > 0 {
+			latencies = append(latencies, r.latency)
+		}
+	}
+	return latencies, errs
+}
+
+// TestNginxPerformanceBaseline establishes a latency/throughput baseline
+// against NGINX. Fixed-count cases (LightLoad, ModerateLoad) and the
+// SustainedLoad case (fixed wall-clock duration at a held target RPS, via
+// -target-rps/-duration) all gate latency only on p99, never the mean,
+// matching how real SLO-style baselines are defined - a handful of slow
+// outliers shouldn't fail the build, but a shifted tail should. Each case
+// also fails if fewer than 99% of its requests succeed.
 func TestNginxPerformanceBaseline(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping performance tests in short mode")
 	}
 
+	nginx := NewNginxComparison("test-server")
+	if !nginx.IsAvailable() {
+		t.Skip("Nginx not available for performance baseline testing")
+	}
+	if err := nginx.Start(); err != nil {
+		t.Fatalf("failed to start nginx: %v", err)
+	}
+	defer nginx.Stop()
+	client := &http.Client{Timeout: requestTimeout}
+
 	type performanceTestCase struct {
-		name         string
-		numRequests  int
+		name          string
+		numRequests   int
 		numConcurrent int
-		description  string
+		p99Threshold  time.Duration
+		description   string
 	}
 
 	testTable := []performanceTestCase{
 		{
-			name:         "LightLoad",
-			numRequests:  20,
+			name:          "LightLoad",
+			numRequests:   20,
 			numConcurrent: 5,
-			description:  "NGINX handles light concurrent load",
+			p99Threshold:  500 * time.Millisecond,
+			description:   "NGINX handles light concurrent load",
 		},
 		{
-			name:         "ModerateLoad",
-			numRequests:  50,
+			name:          "ModerateLoad",
+			numRequests:   50,
 			numConcurrent: 10,
-			description:  "NGINX handles moderate concurrent load",
+			p99Threshold:  750 * time.Millisecond,
+			description:   "NGINX handles moderate concurrent load",
 		},
 	}
 
+	var reports []perfCaseReport
+
 	for _, tc := range testTable {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing NGINX performance baseline: %s", tc.description)
 
-			results := make(chan error, tc.numRequests)
-			semaphore := make(chan struct{}, tc.numConcurrent)
-
 			startTime := time.Now()
-
-			// Launch concurrent requests
-			for i := 0; i < tc.numRequests; i++ {
-				go func(id int) {
-					semaphore <- struct{}{}        // Acquire
-					defer func() { <-semaphore }() // Release
-
-					resp, err := nginxClient.Get(nginxInstance.GetURL(fmt.Sprintf("/?req=%d", id)))
-					if err != nil {
-						results <- err
-						return
-					}
-					resp.Body.Close()
-
-					if resp.StatusCode >= 400 {
-						results <- fmt.Errorf("request %d returned error: %d", id, resp.StatusCode)
-						return
-					}
-
-					results <- nil
-				}(i)
-			}
-
-			// Collect results
-			var errors []error
-			for i := 0; i < tc.numRequests; i++ {
-				if err := <-results; err != nil {
-					errors = append(errors, err)
-				}
-			}
-
+			latencies, errs := runPerfRequests(nginx, client, tc.numRequests, tc.numConcurrent)
 			duration := time.Since(startTime)
 
-			successRate := float64(tc.numRequests-len(errors)) / float64(tc.numRequests) * 100
+			successRate := float64(tc.numRequests-len(errs)) / float64(tc.numRequests) * 100
 			requestsPerSecond := float64(tc.numRequests) / duration.Seconds()
+			stats := computeLatencyStats(latencies)
 
 			t.Logf("NGINX Performance Results:")
 			t.Logf("  Total requests: %d", tc.numRequests)
@@ -783,24 +1223,121 @@ func TestNginxPerformanceBaseline(t *testing.T) {
 			t.Logf("  Duration: %v", duration)
 			t.Logf("  Success rate: %.1f%%", successRate)
 			t.Logf("  Requests/sec: %.1f", requestsPerSecond)
-			t.Logf("  Errors: %d", len(errors))
+			t.Logf("  Errors: %d", len(errs))
+			stats.logTo(t)
 
-			if len(errors) > 0 {
-				t.Logf("  Sample errors: %v", errors[:min(3, len(errors))])
+			if len(errs) > 0 {
+				t.Logf("  Sample errors: %v", errs[:min(3, len(errs))])
 			}
 
+			reports = append(reports, perfCaseReport{
+				Name:              tc.name,
+				NumRequests:       tc.numRequests,
+				NumConcurrent:     tc.numConcurrent,
+				Duration:          duration,
+				SuccessRate:       successRate,
+				RequestsPerSecond: requestsPerSecond,
+				Latency:           stats,
+			})
+
 			// NGINX should handle all requests successfully
 			if successRate < 99.0 {
 				t.Errorf("NGINX success rate %.1f%% is below expected 99%%", successRate)
 			}
+			// Assert on the tail, not the mean: a handful of slow outliers
+			// shouldn't fail the build, but a shifted p99 should.
+			if stats.P99 > tc.p99Threshold {
+				t.Errorf("NGINX p99 latency %v exceeds threshold %v", stats.P99, tc.p99Threshold)
+			}
 		})
 	}
-}
 
-// Helper function for Go versions that don't have built-in min function
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+	t.Run("SustainedLoad", func(t *testing.T) {
+		t.Logf("Testing NGINX sustained load: %d req/s for %v", *perfTargetRPS, *perfDuration)
+
+		limiter := rate.NewLimiter(rate.Limit(*perfTargetRPS), 1)
+		numConcurrent := 50
+		semaphore := make(chan struct{}, numConcurrent)
+
+		var mu sync.Mutex
+		var latencies []time.Duration
+		var errs []error
+		var wg sync.WaitGroup
+
+		ctx, cancel := context.WithTimeout(context.Background(), *perfDuration)
+		defer cancel()
+
+		startTime := time.Now()
+		var sent int
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				break loop
+			default:
+			}
+			if err := limiter.Wait(ctx); err != nil {
+				break
+			}
+
+			sent++
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(id int) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				start := time.Now()
+				resp, err := client.Get(nginx.GetURL(fmt.Sprintf("/?req=%d", id)))
+				elapsed := time.Since(start)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode >= 400 {
+					errs = append(errs, fmt.Errorf("request %d returned error: %d", id, resp.StatusCode))
+				}
+				latencies = append(latencies, elapsed)
+			}(sent)
+		}
+		wg.Wait()
+		duration := time.Since(startTime)
+
+		successRate := float64(sent-len(errs)) / float64(sent) * 100
+		requestsPerSecond := float64(sent) / duration.Seconds()
+		stats := computeLatencyStats(latencies)
+
+		t.Logf("NGINX Sustained Load Results:")
+		t.Logf("  Total requests: %d", sent)
+		t.Logf("  Concurrent workers: %d", numConcurrent)
+		t.Logf("  Duration: %v", duration)
+		t.Logf("  Success rate: %.1f%%", successRate)
+		t.Logf("  Requests/sec: %.1f (target %d)", requestsPerSecond, *perfTargetRPS)
+		t.Logf("  Errors: %d", len(errs))
+		stats.logTo(t)
+
+		reports = append(reports, perfCaseReport{
+			Name:              "SustainedLoad",
+			NumRequests:       sent,
+			NumConcurrent:     numConcurrent,
+			Duration:          duration,
+			SuccessRate:       successRate,
+			RequestsPerSecond: requestsPerSecond,
+			Latency:           stats,
+		})
+
+		if successRate < 99.0 {
+			t.Errorf("NGINX success rate %.1f%% is below expected 99%%", successRate)
+		}
+		const sustainedP99Threshold = time.Second
+		if stats.P99 > sustainedP99Threshold {
+			t.Errorf("NGINX p99 latency %v exceeds threshold %v", stats.P99, sustainedP99Threshold)
+		}
+	})
+
+	writePerfJSON(t, reports)
 }
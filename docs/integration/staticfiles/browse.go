@@ -0,0 +1,166 @@
+// Package staticfiles implements a directory-listing handler modeled on
+// Caddy's browse middleware: a templated HTML listing, JSON content
+// negotiation, and query-string driven sorting. Stock nginx autoindex can
+// only do the first of these, so this package documents (and lets
+// docs/integration test directly) the richer listing behavior webserv's
+// own handler for paths like /public/ should provide.
+package staticfiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfo is one entry in a Listing: a file or subdirectory.
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	IsDir   bool      `json:"is_dir"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Listing is the data a Browse handler renders, either as HTML through its
+// Template or as JSON when the request negotiates it.
+type Listing struct {
+	Name           string
+	Path           string
+	CanGoUp        bool
+	Items          []FileInfo
+	NumDirs        int
+	NumFiles       int
+	Sort           string
+	Order          string
+	ItemsLimitedTo int
+	TotalItems     int
+}
+
+// defaultTemplateSource is deliberately minimal; callers serving a
+// production listing are expected to set Browse.Template to something
+// themed, the way DefaultTemplate documents the minimum fields available.
+const defaultTemplateSource = `<!DOCTYPE html>
+<html>
+<head><title>Index of {{.Path}}</title></head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th></tr>
+{{if .CanGoUp}}<tr><td><a href="..">..</a></td><td></td><td></td></tr>{{end}}
+{{range .Items}}<tr><td><a href="{{.Name}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td>{{.Size}}</td><td>{{.ModTime}}</td></tr>
+{{end}}
+</table>
+{{if .ItemsLimitedTo}}<p>Showing first {{.ItemsLimitedTo}} of {{.TotalItems}} entries.</p>{{end}}
+</body>
+</html>
+`
+
+// DefaultTemplate is the HTML template Browse renders a Listing with when
+// its own Template field is nil.
+var DefaultTemplate = template.Must(template.New("listing").Parse(defaultTemplateSource))
+
+// Browse serves directory listings, rendering HTML through Template (or
+// DefaultTemplate if nil) unless the request negotiates JSON.
+type Browse struct {
+	Template *template.Template
+}
+
+// NewBrowse builds a Browse handler using DefaultTemplate.
+func NewBrowse() *Browse {
+	return &Browse{Template: DefaultTemplate}
+}
+
+// ServeListing reads dir from the filesystem, builds a Listing honoring
+// r's sort/order/limit query parameters, and writes it to w as JSON (if r
+// negotiates it via Accept: application/json) or HTML otherwise.
+func (b *Browse) ServeListing(w http.ResponseWriter, r *http.Request, dir, urlPath string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("staticfiles: reading %s: %w", dir, err)
+	}
+
+	query := r.URL.Query()
+	listing := Listing{
+		Name:    path.Base(urlPath),
+		Path:    urlPath,
+		CanGoUp: urlPath != "/" && urlPath != "",
+		Sort:    query.Get("sort"),
+		Order:   query.Get("order"),
+	}
+	if listing.Sort == "" {
+		listing.Sort = "name"
+	}
+	if listing.Order == "" {
+		listing.Order = "asc"
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			listing.NumDirs++
+		} else {
+			listing.NumFiles++
+		}
+		listing.Items = append(listing.Items, FileInfo{
+			Name:    entry.Name(),
+			Size:    info.Size(),
+			IsDir:   info.IsDir(),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sortItems(listing.Items, listing.Sort, listing.Order)
+	listing.TotalItems = len(listing.Items)
+
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit < len(listing.Items) {
+		listing.Items = listing.Items[:limit]
+		listing.ItemsLimitedTo = limit
+	}
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(listing.Items)
+	}
+
+	tmpl := b.Template
+	if tmpl == nil {
+		tmpl = DefaultTemplate
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return tmpl.Execute(w, listing)
+}
+
+// wantsJSON reports whether r negotiated a JSON response via its Accept
+// header.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// sortItems sorts items in place by the "name", "size", or "time" field
+// named by by (defaulting to "name"), ascending unless order is "desc".
+func sortItems(items []FileInfo, by, order string) {
+	less := func(i, j int) bool {
+		switch by {
+		case "size":
+			return items[i].Size < items[j].Size
+		case "time":
+			return items[i].ModTime.Before(items[j].ModTime)
+		default:
+			return items[i].Name < items[j].Name
+		}
+	}
+	if order == "desc" {
+		ascending := less
+		less = func(i, j int) bool { return ascending(j, i) }
+	}
+	sort.Slice(items, less)
+}
@@ -0,0 +1,141 @@
+// Package try offers retry-until-ready primitives for integration tests
+// that need to wait on an external process (nginx, a container, webserv
+// itself) becoming responsive, instead of a fixed time.Sleep followed by
+// a fixed-count poll loop.
+package try
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Condition inspects a response and returns an error describing why it
+// doesn't yet satisfy the caller's expectation, or nil if it does.
+type Condition func(resp *http.Response) error
+
+// StatusCodeIs returns a Condition satisfied once the response's status
+// code equals want.
+func StatusCodeIs(want int) Condition {
+	return func(resp *http.Response) error {
+		if resp.StatusCode != want {
+			return fmt.Errorf("got status %d, want %d", resp.StatusCode, want)
+		}
+		return nil
+	}
+}
+
+// BodyContains returns a Condition satisfied once the response body
+// contains substr. It drains and replaces resp.Body with a fresh reader
+// so later conditions and the caller can still read it.
+func BodyContains(substr string) Condition {
+	return func(resp *http.Response) error {
+		body, err := drainBody(resp)
+		if err != nil {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		if !bytes.Contains(body, []byte(substr)) {
+			return fmt.Errorf("body does not contain %q", substr)
+		}
+		return nil
+	}
+}
+
+// HeaderEquals returns a Condition satisfied once header equals want.
+func HeaderEquals(header, want string) Condition {
+	return func(resp *http.Response) error {
+		if got := resp.Header.Get(header); got != want {
+			return fmt.Errorf("header %s = %q, want %q", header, got, want)
+		}
+		return nil
+	}
+}
+
+func drainBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ciMultiplier scales every timeout passed to this package by the
+// CI_MULTIPLIER env var (default 1), so a slower CI runner gets
+// proportionally longer to become ready instead of flaking.
+func ciMultiplier() float64 {
+	v := os.Getenv("CI_MULTIPLIER")
+	if v == "" {
+		return 1
+	}
+	m, err := strconv.ParseFloat(v, 64)
+	if err != nil || m <= 0 {
+		return 1
+	}
+	return m
+}
+
+// Response calls do repeatedly, backing off exponentially between
+// attempts, until do succeeds and every condition passes, or timeout
+// (scaled by CI_MULTIPLIER) elapses. On timeout it returns the last
+// response received (if any) and an error describing the last unmet
+// condition or request failure.
+func Response(timeout time.Duration, do func() (*http.Response, error), conditions ...Condition) (*http.Response, error) {
+	deadline := time.Now().Add(time.Duration(float64(timeout) * ciMultiplier()))
+
+	const maxBackoff = 500 * time.Millisecond
+	backoff := 10 * time.Millisecond
+
+	var lastErr error
+	for {
+		resp, err := do()
+		if err != nil {
+			lastErr = err
+		} else if condErr := firstUnmet(resp, conditions); condErr != nil {
+			lastErr = condErr
+		} else {
+			return resp, nil
+		}
+
+		if time.Now().After(deadline) {
+			var resultResp *http.Response
+			if err == nil {
+				resultResp = resp
+			}
+			return resultResp, fmt.Errorf("condition not met within %v: %w", timeout, lastErr)
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func firstUnmet(resp *http.Response, conditions []Condition) error {
+	for _, cond := range conditions {
+		if err := cond(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRequest issues repeated GET requests against url until the server
+// responds and every condition passes, or timeout (scaled by
+// CI_MULTIPLIER) elapses.
+func GetRequest(url string, timeout time.Duration, conditions ...Condition) (*http.Response, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return Response(timeout, func() (*http.Response, error) {
+		return client.Get(url)
+	}, conditions...)
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/umarudotdev/selfserv/tests/integration/testlog"
+)
+
+// suiteReport accumulates per-test results for the JUnit/TAP output written
+// in TestMain; see runCorrelated.
+var suiteReport = testlog.NewReport("integration")
+
+// serverLog tails test-server/server.log, the file the harness redirects the
+// C++ server's stderr into.
+var serverLog = testlog.NewServerLog("test-server/server.log")
+
+// TestMain emits reports/junit.xml and reports/tap.txt once the suite
+// finishes, in addition to the normal go test output, so CI dashboards can
+// consume either format.
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	if err := os.MkdirAll("reports", 0755); err == nil {
+		if err := suiteReport.WriteJUnit("reports/junit.xml"); err != nil {
+			fmt.Fprintf(os.Stderr, "testlog: failed to write junit report: %v\n", err)
+		}
+		if err := suiteReport.WriteTAP("reports/tap.txt"); err != nil {
+			fmt.Fprintf(os.Stderr, "testlog: failed to write TAP report: %v\n", err)
+		}
+	}
+
+	os.Exit(code)
+}
+
+// runCorrelated runs fn as a request against path with a fresh X-Test-ID,
+// records the result in suiteReport, and, if the subtest failed, logs only
+// the slice of test-server/server.log produced while it ran — so a failing
+// t.Parallel() case can be traced through interleaved server output instead
+// of scrolling through the whole log.
+func runCorrelated(t *testing.T, name, path string) {
+	t.Helper()
+
+	testID := testlog.CorrelationID()
+	offset := serverLog.Offset()
+	start := time.Now()
+
+	t.Run(name, func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest("GET", getTestURL(path), nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Test-ID", testID)
+
+		resp, err := createTestClient().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		io.ReadAll(resp.Body)
+
+		if resp.StatusCode >= 500 {
+			t.Errorf("unexpected server error for %s: %d", path, resp.StatusCode)
+		}
+	})
+
+	result := testlog.CaseResult{
+		Name:     name,
+		Duration: time.Since(start),
+		Failed:   t.Failed(),
+		Skipped:  t.Skipped(),
+	}
+
+	if result.Failed {
+		if slice, err := serverLog.Since(offset); err == nil {
+			correlated := testlog.GrepTestID(slice, testID)
+			if correlated != "" {
+				t.Logf("server log for %s (X-Test-ID=%s):\n%s", name, testID, correlated)
+			} else {
+				t.Logf("server log for %s (X-Test-ID=%s): no matching lines in test-server/server.log", name, testID)
+			}
+		}
+	}
+
+	suiteReport.Add(result)
+}
+
+// TestCorrelatedRequests demonstrates the testlog wiring: a handful of
+// requests tagged with X-Test-ID, whose failures would be traceable through
+// the server log even when run with -parallel alongside other subtests.
+func TestCorrelatedRequests(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+	}{
+		{name: "RootIndex", path: "/"},
+		{name: "PublicDirectory", path: "/public/"},
+	}
+
+	for _, tc := range cases {
+		runCorrelated(t, tc.name, tc.path)
+	}
+}
@@ -0,0 +1,262 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FastCGI record types, per the spec webserv's client-side multiplexer speaks.
+const (
+	fcgiBeginRequest = 1
+	fcgiParams       = 4
+	fcgiStdin        = 5
+	fcgiStdout       = 6
+	fcgiStderr       = 7
+	fcgiEndRequest   = 3
+
+	fcgiResponder = 1
+)
+
+// fcgiRecord is the 8-byte FastCGI record header plus its content.
+type fcgiRecord struct {
+	recordType uint8
+	requestID  uint16
+	content    []byte
+}
+
+func (r fcgiRecord) encode() []byte {
+	padding := (8 - len(r.content)%8) % 8
+	buf := make([]byte, 8+len(r.content)+padding)
+	buf[0] = 1 // version
+	buf[1] = r.recordType
+	binary.BigEndian.PutUint16(buf[2:4], r.requestID)
+	binary.BigEndian.PutUint16(buf[4:6], uint16(len(r.content)))
+	buf[6] = uint8(padding)
+	copy(buf[8:], r.content)
+	return buf
+}
+
+func readFCGIRecord(r io.Reader) (fcgiRecord, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fcgiRecord{}, err
+	}
+	contentLen := binary.BigEndian.Uint16(header[4:6])
+	paddingLen := header[6]
+
+	content := make([]byte, contentLen)
+	if contentLen > 0 {
+		if _, err := io.ReadFull(r, content); err != nil {
+			return fcgiRecord{}, err
+		}
+	}
+	if paddingLen > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(paddingLen)); err != nil {
+			return fcgiRecord{}, err
+		}
+	}
+
+	return fcgiRecord{
+		recordType: header[1],
+		requestID:  binary.BigEndian.Uint16(header[2:4]),
+		content:    content,
+	}, nil
+}
+
+// encodeFCGIParams encodes a FastCGI name-value pair list using the
+// single-byte length form (params used by this harness all fit in 127 bytes).
+func encodeFCGIParams(params map[string]string) []byte {
+	var buf bytes.Buffer
+	for k, v := range params {
+		buf.WriteByte(byte(len(k)))
+		buf.WriteByte(byte(len(v)))
+		buf.WriteString(k)
+		buf.WriteString(v)
+	}
+	return buf.Bytes()
+}
+
+// fcgiTestCase describes one FastCGI request/response scenario.
+type fcgiTestCase struct {
+	name           string
+	method         string
+	scriptName     string
+	queryString    string
+	contentType    string
+	body           []byte
+	chunked        bool
+	expectedStatus int
+	expectBody     string
+}
+
+var fastcgiTable = []fcgiTestCase{
+	{
+		name:           "GetWithQueryString",
+		method:         "GET",
+		scriptName:     "/fcgi-bin/echo.py",
+		queryString:    "name=webserv&test=true",
+		expectedStatus: 200,
+	},
+	{
+		name:           "PostURLEncoded",
+		method:         "POST",
+		scriptName:     "/fcgi-bin/echo.py",
+		contentType:    "application/x-www-form-urlencoded",
+		body:           []byte("field=value&other=1"),
+		expectedStatus: 200,
+	},
+	{
+		name:           "PostChunkedBody",
+		method:         "POST",
+		scriptName:     "/fcgi-bin/echo.py",
+		contentType:    "application/x-www-form-urlencoded",
+		body:           []byte("chunked=payload"),
+		chunked:        true,
+		expectedStatus: 200,
+	},
+	{
+		name:           "StderrIsLoggedNotReturned",
+		method:         "GET",
+		scriptName:     "/fcgi-bin/warn.py",
+		expectedStatus: 200,
+	},
+	{
+		name:           "StatusHeaderIsHonored",
+		method:         "GET",
+		scriptName:     "/fcgi-bin/teapot.py",
+		expectedStatus: 418,
+	},
+	{
+		name:           "EarlyStdoutCloseYields502",
+		method:         "GET",
+		scriptName:     "/fcgi-bin/crash.py",
+		expectedStatus: 502,
+	},
+}
+
+// TestWebservFastCGI drives webserv's client-side FastCGI multiplexer over
+// HTTP, exercising the scenarios in fastcgiTable. It requires test.conf to
+// point /fcgi-bin/ at a FastCGI upstream serving echo.py, warn.py,
+// teapot.py and crash.py; of these, only echo.py ships in this repository,
+// under test-server/cgi-bin/. If that upstream isn't reachable (404 on the first
+// probe) the suite is skipped rather than failed, matching how the classic
+// CGI tests degrade when CGI isn't configured.
+func TestWebservFastCGI(t *testing.T) {
+	client := createTestClient()
+
+	probe, err := client.Get(getTestURL("/fcgi-bin/echo.py"))
+	require.NoError(t, err)
+	probe.Body.Close()
+	if probe.StatusCode == 404 {
+		t.Skip("FastCGI upstream not configured for /fcgi-bin/")
+	}
+
+	for _, tc := range fastcgiTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uri := tc.scriptName
+			if tc.queryString != "" {
+				uri += "?" + tc.queryString
+			}
+
+			req, err := http.NewRequest(tc.method, getTestURL(uri), bodyReaderFor(tc))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.chunked {
+				req.TransferEncoding = []string{"chunked"}
+				req.ContentLength = -1
+			}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectBody != "" {
+				assert.Contains(t, string(body), tc.expectBody)
+			}
+		})
+	}
+}
+
+func bodyReaderFor(tc fcgiTestCase) io.Reader {
+	if len(tc.body) == 0 {
+		return nil
+	}
+	return bytes.NewReader(tc.body)
+}
+
+// TestFastCGIRawProtocol frames FCGI_BEGIN_REQUEST/FCGI_PARAMS/FCGI_STDIN
+// directly over TCP against a FastCGI upstream, bypassing webserv entirely,
+// to pin down the exact wire format webserv's client is expected to speak.
+// It is skipped unless a local stub FastCGI responder is listening, since
+// this repository does not ship one.
+func TestFastCGIRawProtocol(t *testing.T) {
+	const upstream = "127.0.0.1:9000"
+
+	conn, err := net.DialTimeout("tcp", upstream, 500*time.Millisecond)
+	if err != nil {
+		t.Skipf("no FastCGI upstream listening on %s: %v", upstream, err)
+	}
+	defer conn.Close()
+
+	const requestID = 1
+
+	begin := fcgiRecord{
+		recordType: fcgiBeginRequest,
+		requestID:  requestID,
+		content:    []byte{0, fcgiResponder, 0, 0, 0, 0, 0, 0},
+	}
+
+	params := encodeFCGIParams(map[string]string{
+		"REQUEST_METHOD":  "GET",
+		"SCRIPT_NAME":     "/fcgi-bin/echo.py",
+		"QUERY_STRING":    "raw=1",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+	})
+
+	var out bytes.Buffer
+	out.Write(begin.encode())
+	out.Write(fcgiRecord{recordType: fcgiParams, requestID: requestID, content: params}.encode())
+	out.Write(fcgiRecord{recordType: fcgiParams, requestID: requestID}.encode()) // empty terminator
+	out.Write(fcgiRecord{recordType: fcgiStdin, requestID: requestID}.encode())  // empty stdin (GET)
+
+	_, err = conn.Write(out.Bytes())
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	for {
+		rec, err := readFCGIRecord(conn)
+		require.NoError(t, err)
+
+		switch rec.recordType {
+		case fcgiStdout:
+			stdout.Write(rec.content)
+		case fcgiStderr:
+			stderr.Write(rec.content)
+		case fcgiEndRequest:
+			goto done
+		}
+	}
+done:
+
+	assert.True(t, strings.HasPrefix(stdout.String(), "Status:") || strings.Contains(stdout.String(), "\r\n\r\n"),
+		"FastCGI stdout should look like a CGI-style response (optional Status line + headers + blank line + body)")
+	t.Logf("stderr from upstream: %q", stderr.String())
+}
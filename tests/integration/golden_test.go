@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden regenerates the golden fixtures in testdata/golden/ instead of
+// comparing against them.
+var updateGolden = flag.Bool("update", false, "regenerate testdata/golden/ fixtures instead of comparing against them")
+
+// goldenMaskPatterns replaces volatile response fields with stable
+// placeholders before comparing against a fixture.
+var goldenMaskPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?m)^Date: .*\r\n`), "Date: {{DATE}}\r\n"},
+	{regexp.MustCompile(`(?m)^Server: .*\r\n`), "Server: {{SERVER}}\r\n"},
+	{regexp.MustCompile(`(?m)^ETag: .*\r\n`), "ETag: {{ETAG}}\r\n"},
+}
+
+// maskVolatileFields applies goldenMaskPatterns to a raw response.
+func maskVolatileFields(raw string) string {
+	for _, m := range goldenMaskPatterns {
+		raw = m.pattern.ReplaceAllString(raw, m.replacement)
+	}
+	return raw
+}
+
+// readRawResponse reads one full HTTP response off conn verbatim (status
+// line, headers in original order and casing, blank line, then a body sized
+// by Content-Length if present) rather than parsing it through http.Client,
+// so header ordering and casing survive for comparison.
+func readRawResponse(t *testing.T, conn net.Conn) string {
+	t.Helper()
+
+	reader := bufio.NewReader(conn)
+	var raw []byte
+
+	statusLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	raw = append(raw, statusLine...)
+
+	contentLength := -1
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		raw = append(raw, line...)
+
+		if line == "\r\n" || line == "\n" {
+			break
+		}
+
+		var length int
+		if n, scanErr := fmt.Sscanf(line, "Content-Length: %d", &length); scanErr == nil && n == 1 {
+			contentLength = length
+		}
+	}
+
+	if contentLength > 0 {
+		body := make([]byte, contentLength)
+		_, err := io.ReadFull(reader, body)
+		require.NoError(t, err)
+		raw = append(raw, body...)
+	}
+
+	return string(raw)
+}
+
+// assertGoldenResponse compares a masked raw response against
+// testdata/golden/<name>.golden, printing a unified diff on mismatch. With
+// -update it (re)writes the fixture instead of comparing.
+func assertGoldenResponse(t *testing.T, name, rawResponse string) {
+	t.Helper()
+
+	masked := maskVolatileFields(rawResponse)
+	path := filepath.Join("testdata", "golden", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(masked), 0644))
+		t.Logf("updated golden fixture %s", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden fixture %s does not exist; run `go test -run %s -update` to create it", path, t.Name())
+	}
+	require.NoError(t, err)
+
+	if string(want) != masked {
+		t.Errorf("golden mismatch for %s:\n%s", name, unifiedDiff(string(want), masked))
+	}
+}
+
+// unifiedDiff renders a minimal line-based diff between two strings; good
+// enough to point at the first divergence without pulling in a diff library.
+func unifiedDiff(want, got string) string {
+	wantLines := splitLines(want)
+	gotLines := splitLines(got)
+
+	var out string
+	max := len(wantLines)
+	if len(gotLines) > max {
+		max = len(gotLines)
+	}
+	for i := 0; i < max; i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			out += fmt.Sprintf("line %d:\n- %q\n+ %q\n", i+1, w, g)
+		}
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	var cur []rune
+	for _, r := range s {
+		cur = append(cur, r)
+		if r == '\n' {
+			lines = append(lines, string(cur))
+			cur = nil
+		}
+	}
+	if len(cur) > 0 {
+		lines = append(lines, string(cur))
+	}
+	return lines
+}
+
+// TestGoldenStaticIndex pins the exact bytes of the static index response
+// (header order, casing, and Connection behavior included) against a golden
+// fixture, catching regressions that loose string-Contains assertions miss.
+func TestGoldenStaticIndex(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", testServerHost, testServerPort), testTimeout)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+
+	raw := readRawResponse(t, conn)
+	assertGoldenResponse(t, "static_index", raw)
+}
+
+// TestGoldenNotFound pins the exact 404 error page bytes.
+func TestGoldenNotFound(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", testServerHost, testServerPort), testTimeout)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /does-not-exist.html HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+
+	raw := readRawResponse(t, conn)
+	assertGoldenResponse(t, "not_found", raw)
+}
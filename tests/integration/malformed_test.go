@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// malformedCase sends a deliberately broken request, verbatim, over a raw
+// TCP connection and asserts the status code the server must reply with.
+// raw is written exactly as given, so it must include its own line endings.
+type malformedCase struct {
+	name           string
+	raw            string
+	expectedStatus int
+}
+
+var malformedRequestTable = []malformedCase{
+	{
+		name:           "BareLFRequestLine",
+		raw:            "GET / HTTP/1.1\nHost: localhost\n\n",
+		expectedStatus: 400,
+	},
+	{
+		name:           "ObsFoldHeaderLine",
+		raw:            "GET / HTTP/1.1\r\nHost: localhost\r\nX-Folded: first\r\n second\r\n\r\n",
+		expectedStatus: 400,
+	},
+	{
+		name:           "DuplicateContentLengthMismatch",
+		raw:            "POST /upload HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nContent-Length: 10\r\n\r\nhello",
+		expectedStatus: 400,
+	},
+	{
+		name:           "ContentLengthAndChunkedTogether",
+		raw:            "POST /upload HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+		expectedStatus: 400,
+	},
+	{
+		name:           "InvalidChunkSizeNonHex",
+		raw:            "POST /upload HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\nZZZZ\r\nhello\r\n0\r\n\r\n",
+		expectedStatus: 400,
+	},
+	{
+		name:           "ChunkExtensionIsIgnored",
+		raw:            "POST /upload HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\n5;foo=bar\r\nhello\r\n0\r\n\r\n",
+		expectedStatus: 200,
+	},
+	{
+		name:           "OversizedRequestLine",
+		raw:            "GET /" + strings.Repeat("a", 9*1024) + " HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		expectedStatus: 414,
+	},
+	{
+		name:           "OversizedHeaderBlock",
+		raw:            "GET / HTTP/1.1\r\nHost: localhost\r\n" + oversizedHeaderBlock() + "\r\n\r\n",
+		expectedStatus: 431,
+	},
+	{
+		name:           "NULByteInHeaderName",
+		raw:            "GET / HTTP/1.1\r\nHost: localhost\r\nX-Bad\x00Name: value\r\n\r\n",
+		expectedStatus: 400,
+	},
+	{
+		name:           "EmbeddedCRInHeaderValue",
+		raw:            "GET / HTTP/1.1\r\nHost: localhost\r\nX-Injected: value\rSet-Cookie: evil\r\n\r\n",
+		expectedStatus: 400,
+	},
+}
+
+// oversizedHeaderBlock builds enough header bytes to exceed any reasonable
+// 8KiB header-section limit without tripping the request-line limit.
+func oversizedHeaderBlock() string {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "X-Filler-%d: %s\r\n", i, strings.Repeat("x", 200))
+	}
+	return b.String()
+}
+
+// TestWebservMalformed injects deliberately broken HTTP/1.1 framings over raw
+// TCP and asserts the server responds with the correct error status rather
+// than crashing or hanging, extending the raw-socket approach TestKeepAlive
+// already uses.
+func TestWebservMalformed(t *testing.T) {
+	for _, tc := range malformedRequestTable {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", testServerHost, testServerPort), testTimeout)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			require.NoError(t, conn.SetDeadline(time.Now().Add(testTimeout)))
+
+			_, err = conn.Write([]byte(tc.raw))
+			require.NoError(t, err)
+
+			reader := bufio.NewReader(conn)
+			resp, err := http.ReadResponse(reader, nil)
+			require.NoError(t, err, "server should reply rather than hang up or crash")
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+// TestWebservSlowLoris drip-feeds a request one byte every 500ms up to the
+// configured timeout and verifies the server eventually responds (closing
+// the connection or returning a timeout status) instead of hanging forever
+// or crashing the worker handling the connection.
+func TestWebservSlowLoris(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping slow-loris test in short mode")
+	}
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", testServerHost, testServerPort), testTimeout)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	request := "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(20*time.Second)))
+
+	for i := 0; i < len(request); i++ {
+		_, err := conn.Write([]byte{request[i]})
+		if err != nil {
+			// The server closed the connection before we finished drip-feeding,
+			// which is an acceptable way to handle a slow client.
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err, "server should eventually respond to a slowly completed request")
+	defer resp.Body.Close()
+
+	assert.NotZero(t, resp.StatusCode)
+}
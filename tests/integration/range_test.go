@@ -0,0 +1,325 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeFixture is served at this path by test.conf and is expected to be
+// exactly 11 bytes ("helloworld\n") so the out-of-range and unsatisfiable
+// cases below have a known file size to reason about.
+const rangeFixture = "/range-test.txt"
+const rangeFixtureSize = 11
+
+// TestHTTPRangeConformance exercises RFC 7233 byte-range semantics, mirroring
+// the coverage matrix in Go's net/http ServeFileRangeTests.
+func TestHTTPRangeConformance(t *testing.T) {
+	client := createTestClient()
+
+	t.Run("SingleRanges", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			rangeHeader  string
+			expectStatus int
+			expectRange  string
+		}{
+			{name: "FirstFiveBytes", rangeHeader: "bytes=0-4", expectStatus: 206, expectRange: fmt.Sprintf("bytes 0-4/%d", rangeFixtureSize)},
+			{name: "FromOffsetToEnd", rangeHeader: "bytes=2-", expectStatus: 206, expectRange: fmt.Sprintf("bytes 2-%d/%d", rangeFixtureSize-1, rangeFixtureSize)},
+			{name: "LastFiveBytes", rangeHeader: "bytes=-5", expectStatus: 206, expectRange: fmt.Sprintf("bytes %d-%d/%d", rangeFixtureSize-5, rangeFixtureSize-1, rangeFixtureSize)},
+			{name: "MiddleSlice", rangeHeader: "bytes=3-7", expectStatus: 206, expectRange: fmt.Sprintf("bytes 3-7/%d", rangeFixtureSize)},
+			{name: "OutOfRangeClampsToSize", rangeHeader: "bytes=5-1000", expectStatus: 206, expectRange: fmt.Sprintf("bytes 5-%d/%d", rangeFixtureSize-1, rangeFixtureSize)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+				require.NoError(t, err)
+				req.Header.Set("Range", tt.rangeHeader)
+
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				assert.Equal(t, tt.expectStatus, resp.StatusCode, "Range: %s", tt.rangeHeader)
+				assert.Equal(t, tt.expectRange, resp.Header.Get("Content-Range"))
+			})
+		}
+	})
+
+	t.Run("UnsatisfiableRange", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=100-200")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 416, resp.StatusCode)
+		assert.Equal(t, fmt.Sprintf("bytes */%d", rangeFixtureSize), resp.Header.Get("Content-Range"))
+	})
+
+	t.Run("WastefulRangeFallsBackToFullBody", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=0-,1-,2-,3-,4-")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 200, resp.StatusCode, "overlapping ranges should fall back to a full 200 response")
+	})
+
+	t.Run("MultiRangeDisjoint", func(t *testing.T) {
+		assertMultipartRange(t, client, "bytes=0-0,-2", []rangePart{
+			{start: 0, end: 0},
+			{start: rangeFixtureSize - 2, end: rangeFixtureSize - 1},
+		})
+	})
+
+	t.Run("MultiRangeDisjointExplicit", func(t *testing.T) {
+		assertMultipartRange(t, client, "bytes=0-1,5-8", []rangePart{
+			{start: 0, end: 1},
+			{start: 5, end: 8},
+		})
+	})
+
+	t.Run("IfRangeWithMatchingETag", func(t *testing.T) {
+		plain, err := client.Get(getTestURL(rangeFixture))
+		require.NoError(t, err)
+		etag := plain.Header.Get("ETag")
+		plain.Body.Close()
+		require.NotEmpty(t, etag, "server must emit an ETag for If-Range to be meaningful")
+
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=0-4")
+		req.Header.Set("If-Range", etag)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 206, resp.StatusCode, "matching If-Range ETag should honor the range")
+	})
+
+	t.Run("IfRangeWithStaleETag", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=0-4")
+		req.Header.Set("If-Range", `"stale-etag-does-not-match"`)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 200, resp.StatusCode, "non-matching If-Range should fall back to a full response")
+	})
+
+	t.Run("IfRangeWithLastModified", func(t *testing.T) {
+		plain, err := client.Get(getTestURL(rangeFixture))
+		require.NoError(t, err)
+		lastModified := plain.Header.Get("Last-Modified")
+		plain.Body.Close()
+		require.NotEmpty(t, lastModified, "server must emit Last-Modified for this If-Range case")
+
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=0-4")
+		req.Header.Set("If-Range", lastModified)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 206, resp.StatusCode, "matching If-Range Last-Modified should honor the range")
+	})
+}
+
+// TestConditionalGet exercises If-None-Match and If-Modified-Since against
+// rangeFixture's weak ETag/Last-Modified, the remaining piece of RFC 7232
+// conditional-request support Range handling above depends on (If-Range
+// already proved the server emits both validators).
+func TestConditionalGet(t *testing.T) {
+	client := createTestClient()
+
+	plain, err := client.Get(getTestURL(rangeFixture))
+	require.NoError(t, err)
+	etag := plain.Header.Get("ETag")
+	lastModified := plain.Header.Get("Last-Modified")
+	plain.Body.Close()
+	require.NotEmpty(t, etag, "server must emit an ETag for If-None-Match to be meaningful")
+	require.NotEmpty(t, lastModified, "server must emit Last-Modified for If-Modified-Since to be meaningful")
+
+	t.Run("IfNoneMatchWithCurrentETag", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", etag)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 304, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, body, "304 must not carry a body")
+	})
+
+	t.Run("IfNoneMatchWithStaleETag", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", `"stale-etag-does-not-match"`)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 200, resp.StatusCode, "non-matching If-None-Match should return the full body")
+	})
+
+	t.Run("IfModifiedSinceNotModified", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-Modified-Since", lastModified)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 304, resp.StatusCode)
+	})
+
+	t.Run("IfModifiedSinceInThePast", func(t *testing.T) {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-Modified-Since", "Mon, 01 Jan 1990 00:00:00 GMT")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 200, resp.StatusCode, "a stale If-Modified-Since should return the full body")
+	})
+
+	t.Run("IfNoneMatchTakesPrecedenceOverIfModifiedSince", func(t *testing.T) {
+		// RFC 7232 §6: when both validators are present, If-None-Match
+		// wins and If-Modified-Since is ignored.
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", `"stale-etag-does-not-match"`)
+		req.Header.Set("If-Modified-Since", lastModified)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 200, resp.StatusCode, "a stale If-None-Match should win over a matching If-Modified-Since")
+	})
+}
+
+// rangePart is the expected Content-Range and body slice for one part of a
+// multipart/byteranges response.
+type rangePart struct {
+	start, end int
+}
+
+// assertMultipartRange requests rangeHeader and verifies the response is a
+// multipart/byteranges message whose parts match expected, in order.
+func assertMultipartRange(t *testing.T, client *http.Client, rangeHeader string, expected []rangePart) {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", rangeHeader)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, 206, resp.StatusCode)
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/byteranges", mediaType)
+	require.NotEmpty(t, params["boundary"])
+
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	var got []rangePart
+	var bodies []string
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		contentRange := part.Header.Get("Content-Range")
+		assert.NotEmpty(t, part.Header.Get("Content-Type"), "each part should carry Content-Type")
+
+		var start, end, size int
+		_, err = fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &size)
+		require.NoError(t, err, "unparsable Content-Range %q", contentRange)
+		assert.Equal(t, rangeFixtureSize, size)
+
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		got = append(got, rangePart{start: start, end: end})
+		bodies = append(bodies, string(data))
+	}
+
+	require.Len(t, got, len(expected))
+	for i, part := range expected {
+		assert.Equal(t, part, got[i], "part %d range mismatch", i)
+		assert.Len(t, bodies[i], part.end-part.start+1, "part %d body length mismatch", i)
+	}
+}
+
+// TestHTTPRangeRoundTrip downloads the fixture in three overlapping ranges
+// and reassembles it to confirm range handling is byte-exact.
+func TestHTTPRangeRoundTrip(t *testing.T) {
+	client := createTestClient()
+
+	fetchRange := func(rangeHeader string) []byte {
+		req, err := http.NewRequest("GET", getTestURL(rangeFixture), nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", rangeHeader)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 206, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return body
+	}
+
+	first := fetchRange("bytes=0-4")
+	second := fetchRange(fmt.Sprintf("bytes=3-%d", rangeFixtureSize-3))
+	third := fetchRange(fmt.Sprintf("bytes=%d-", rangeFixtureSize-5))
+
+	var reassembled strings.Builder
+	reassembled.Write(first)
+	reassembled.Write(second[len(first)-3:])
+	reassembled.Write(third[len(third)-2:])
+
+	full, err := client.Get(getTestURL(rangeFixture))
+	require.NoError(t, err)
+	defer full.Body.Close()
+	fullBody, err := io.ReadAll(full.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(fullBody), reassembled.String())
+}
@@ -0,0 +1,152 @@
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ErrOffsetMismatch is returned by resumableUpload.AppendChunk when a PATCH
+// chunk's Upload-Offset does not match the upload's current offset - the
+// 409 Conflict case for a tus-style resumable upload.
+var ErrOffsetMismatch = errors.New("selfserv: resumable upload offset mismatch")
+
+// resumableUpload is the reference state machine a tus-style `/upload/<id>`
+// route needs: it tracks how many bytes have been accepted so far against
+// the length declared at creation, and refuses chunks that don't start
+// exactly at the current offset.
+type resumableUpload struct {
+	length    int64
+	offset    int64
+	data      bytes.Buffer
+	createdAt time.Time
+	ttl       time.Duration
+}
+
+// newResumableUpload starts a zero-byte upload declaring length bytes will
+// eventually be written, expiring after ttl if never completed.
+func newResumableUpload(length int64, ttl time.Duration, now time.Time) *resumableUpload {
+	return &resumableUpload{length: length, createdAt: now, ttl: ttl}
+}
+
+// AppendChunk appends chunk at offset, the position the client claims its
+// PATCH request starts at. It returns ErrOffsetMismatch without modifying
+// state if offset doesn't match the upload's current offset.
+func (u *resumableUpload) AppendChunk(offset int64, chunk []byte) error {
+	if offset != u.offset {
+		return fmt.Errorf("%w: got %d, want %d", ErrOffsetMismatch, offset, u.offset)
+	}
+	if u.offset+int64(len(chunk)) > u.length {
+		return fmt.Errorf("chunk would overrun declared Upload-Length %d", u.length)
+	}
+	n, _ := u.data.Write(chunk)
+	u.offset += int64(n)
+	return nil
+}
+
+// Offset returns the current Upload-Offset.
+func (u *resumableUpload) Offset() int64 { return u.offset }
+
+// Complete reports whether every declared byte has been written.
+func (u *resumableUpload) Complete() bool { return u.offset == u.length }
+
+// Expired reports whether this upload has outlived its TTL without
+// completing, as of now.
+func (u *resumableUpload) Expired(now time.Time) bool {
+	return !u.Complete() && now.Sub(u.createdAt) > u.ttl
+}
+
+// diskQuota tracks aggregate bytes reserved across in-progress resumable
+// uploads against a fixed global cap.
+type diskQuota struct {
+	capacity int64
+	reserved int64
+}
+
+func newDiskQuota(capacity int64) *diskQuota {
+	return &diskQuota{capacity: capacity}
+}
+
+// Reserve attempts to reserve length bytes for a new upload, returning
+// false if doing so would exceed the quota's capacity.
+func (q *diskQuota) Reserve(length int64) bool {
+	if q.reserved+length > q.capacity {
+		return false
+	}
+	q.reserved += length
+	return true
+}
+
+// Release frees length previously-reserved bytes, e.g. on TTL expiry.
+func (q *diskQuota) Release(length int64) {
+	q.reserved -= length
+}
+
+// Test the resumable-upload state machine and disk-quota bookkeeping a
+// tus-style /upload/<id> route would need, independent of any running
+// server.
+func TestResumableUploadStateMachine(t *testing.T) {
+	t.Run("AppendsSequentialChunksToCompletion", func(t *testing.T) {
+		now := time.Now()
+		u := newResumableUpload(10, time.Hour, now)
+
+		require.NoError(t, u.AppendChunk(0, []byte("hello")))
+		assert.EqualValues(t, 5, u.Offset())
+		assert.False(t, u.Complete())
+
+		require.NoError(t, u.AppendChunk(5, []byte("world")))
+		assert.EqualValues(t, 10, u.Offset())
+		assert.True(t, u.Complete())
+		assert.Equal(t, "helloworld", u.data.String())
+	})
+
+	t.Run("RejectsMismatchedOffsetWithoutMutatingState", func(t *testing.T) {
+		now := time.Now()
+		u := newResumableUpload(10, time.Hour, now)
+		require.NoError(t, u.AppendChunk(0, []byte("hello")))
+
+		err := u.AppendChunk(0, []byte("retry-old-offset"))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrOffsetMismatch))
+		assert.EqualValues(t, 5, u.Offset(), "a rejected chunk must not advance the offset")
+	})
+
+	t.Run("RejectsChunkThatWouldOverrunDeclaredLength", func(t *testing.T) {
+		now := time.Now()
+		u := newResumableUpload(5, time.Hour, now)
+		err := u.AppendChunk(0, []byte("too many bytes"))
+		assert.Error(t, err)
+	})
+
+	t.Run("ExpiresIncompleteUploadsAfterTTL", func(t *testing.T) {
+		start := time.Now()
+		u := newResumableUpload(10, time.Minute, start)
+		assert.False(t, u.Expired(start.Add(30*time.Second)))
+		assert.True(t, u.Expired(start.Add(2*time.Minute)))
+
+		require.NoError(t, u.AppendChunk(0, make([]byte, 10)))
+		assert.False(t, u.Expired(start.Add(2*time.Minute)), "a completed upload never expires")
+	})
+}
+
+func TestDiskQuota(t *testing.T) {
+	t.Run("RejectsReservationsOverCapacity", func(t *testing.T) {
+		q := newDiskQuota(100)
+		assert.True(t, q.Reserve(60))
+		assert.False(t, q.Reserve(60), "second reservation would exceed capacity")
+		assert.True(t, q.Reserve(40), "capacity should still have room for the remainder")
+	})
+
+	t.Run("ReleaseFreesCapacityForReuse", func(t *testing.T) {
+		q := newDiskQuota(100)
+		require.True(t, q.Reserve(100))
+		require.False(t, q.Reserve(1))
+		q.Release(100)
+		assert.True(t, q.Reserve(1))
+	})
+}
@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ErrRetryAfter is the sentinel a handler or CGI script wraps to signal
+// that a request should be rejected with 503 and a Retry-After hint,
+// instead of being silently dropped or left to time out. errors.Is(err,
+// ErrRetryAfter) matches any wrapped instance.
+var ErrRetryAfter = errors.New("selfserv: retry after backpressure")
+
+// retryAfterError wraps ErrRetryAfter with the delay the caller should
+// report in the response's Retry-After header.
+type retryAfterError struct {
+	delay time.Duration
+}
+
+// NewRetryAfterError returns an error wrapping ErrRetryAfter that carries
+// delay, so callers can both errors.Is-match it and recover the delay via
+// errors.As.
+func NewRetryAfterError(delay time.Duration) error {
+	return &retryAfterError{delay: delay}
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("%s: retry in %v", ErrRetryAfter, e.delay)
+}
+
+func (e *retryAfterError) Unwrap() error { return ErrRetryAfter }
+
+// Delay returns the delay this error carries, for errors.As callers.
+func (e *retryAfterError) Delay() time.Duration { return e.delay }
+
+// FormatRetryAfter renders d as an RFC 7231 Retry-After value in seconds,
+// the simpler of its two legal forms (the other being an HTTP-date).
+func FormatRetryAfter(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return strconv.Itoa(seconds)
+}
+
+// ParseRetryAfter parses a Retry-After header value in either legal form
+// (delay-seconds or an HTTP-date) into a duration from now.
+func ParseRetryAfter(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty Retry-After header")
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, fmt.Errorf("unparsable Retry-After %q: %w", value, err)
+	}
+	return time.Until(when), nil
+}
+
+// Test the ErrRetryAfter sentinel and the Retry-After format/parse helpers
+// in isolation, independent of any running server.
+func TestRetryAfterError(t *testing.T) {
+	t.Run("ErrorsIsMatchesWrappedInstance", func(t *testing.T) {
+		err := fmt.Errorf("upstream saturated: %w", NewRetryAfterError(2*time.Second))
+		assert.True(t, errors.Is(err, ErrRetryAfter))
+
+		var rae *retryAfterError
+		require.True(t, errors.As(err, &rae))
+		assert.Equal(t, 2*time.Second, rae.Delay())
+	})
+
+	t.Run("FormatRetryAfterRendersSeconds", func(t *testing.T) {
+		assert.Equal(t, "5", FormatRetryAfter(5*time.Second))
+		assert.Equal(t, "0", FormatRetryAfter(-time.Second), "a negative delay should clamp to 0, not render negative")
+	})
+
+	t.Run("ParseRetryAfterSeconds", func(t *testing.T) {
+		d, err := ParseRetryAfter("3")
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, d)
+	})
+
+	t.Run("ParseRetryAfterHTTPDate", func(t *testing.T) {
+		future := time.Now().Add(10 * time.Second).UTC()
+		d, err := ParseRetryAfter(future.Format(http.TimeFormat))
+		require.NoError(t, err)
+		assert.InDelta(t, float64(10*time.Second), float64(d), float64(2*time.Second))
+	})
+
+	t.Run("ParseRetryAfterRejectsGarbage", func(t *testing.T) {
+		_, err := ParseRetryAfter("not-a-retry-value")
+		assert.Error(t, err)
+	})
+}
+
+// Test that saturating the server beyond its worker pool / per-IP
+// concurrency cap yields a 503 with a Retry-After every rejected request
+// can parse via ParseRetryAfter above, rather than a silent drop or a
+// client-side timeout.
+//
+// webserv has no worker-pool/per-IP concurrency cap, 503-with-backpressure
+// path, or Retry-After emission checked into this tree, so there is
+// nothing to saturate yet. TestStressMaxOpenConnections is the closest
+// thing this repo can already exercise - clean backpressure at the TCP
+// accept level - but without a Retry-After contract to assert against.
+func TestServerBackpressure503(t *testing.T) {
+	t.Skip("webserv has no 503/Retry-After backpressure path checked into this tree; nothing to test yet")
+}
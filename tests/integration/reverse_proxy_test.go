@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backendHealth tracks passive health for one proxy_pass upstream: it goes
+// down after failureThreshold consecutive failures, and allows exactly one
+// half-open probe request through once cooldown has elapsed.
+type backendHealth struct {
+	failureThreshold int
+	cooldown         time.Duration
+
+	consecutiveFailures int
+	downSince           time.Time
+	probing             bool
+}
+
+// newBackendHealth returns a backendHealth that marks its backend down
+// after failureThreshold consecutive failed requests, and re-probes it
+// once cooldown has elapsed since the last failure.
+func newBackendHealth(failureThreshold int, cooldown time.Duration) *backendHealth {
+	return &backendHealth{failureThreshold: failureThreshold, cooldown: cooldown}
+}
+
+// Available reports whether a request should be routed to this backend at
+// now: healthy backends are always available; a down backend becomes
+// available for exactly one half-open probe once cooldown has elapsed.
+func (b *backendHealth) Available(now time.Time) bool {
+	if b.consecutiveFailures < b.failureThreshold {
+		return true
+	}
+	if b.probing {
+		return false
+	}
+	if now.Sub(b.downSince) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// RecordResult updates health state from the outcome of a request that
+// Available(now) most recently admitted.
+func (b *backendHealth) RecordResult(now time.Time, success bool) {
+	wasProbing := b.probing
+	b.probing = false
+	if success {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	if wasProbing || b.consecutiveFailures == b.failureThreshold {
+		// A fresh trip of the threshold, or a failed half-open probe,
+		// both restart the cooldown window from now.
+		b.downSince = now
+	}
+}
+
+// roundRobin cycles through a fixed set of indices, skipping any that pick
+// reports unavailable, for round-robin dispatch across proxy_pass backends.
+type roundRobin struct {
+	n    int
+	next int
+}
+
+func newRoundRobin(n int) *roundRobin {
+	return &roundRobin{n: n}
+}
+
+// Pick returns the index of the next available backend, or -1 if none of
+// the n backends are available.
+func (r *roundRobin) Pick(available func(i int) bool) int {
+	for i := 0; i < r.n; i++ {
+		idx := r.next % r.n
+		r.next++
+		if available(idx) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Test the passive-health and round-robin reference logic a proxy_pass
+// implementation would need, independent of any running server.
+func TestBackendHealth(t *testing.T) {
+	t.Run("StaysAvailableUnderThreshold", func(t *testing.T) {
+		h := newBackendHealth(5, time.Minute)
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			require.True(t, h.Available(now))
+			h.RecordResult(now, false)
+		}
+		assert.True(t, h.Available(now), "4 failures should not yet trip a threshold of 5")
+	})
+
+	t.Run("EjectsAfterConsecutiveFailures", func(t *testing.T) {
+		h := newBackendHealth(5, time.Minute)
+		now := time.Now()
+		for i := 0; i < 5; i++ {
+			require.True(t, h.Available(now))
+			h.RecordResult(now, false)
+		}
+		assert.False(t, h.Available(now), "backend should be down after 5 consecutive failures")
+	})
+
+	t.Run("SuccessResetsFailureCount", func(t *testing.T) {
+		h := newBackendHealth(3, time.Minute)
+		now := time.Now()
+		h.RecordResult(now, false)
+		h.RecordResult(now, false)
+		h.RecordResult(now, true)
+		h.RecordResult(now, false)
+		h.RecordResult(now, false)
+		assert.True(t, h.Available(now), "a success should reset the consecutive-failure streak")
+	})
+
+	t.Run("HalfOpenProbeAfterCooldown", func(t *testing.T) {
+		h := newBackendHealth(2, 10*time.Second)
+		start := time.Now()
+		h.RecordResult(start, false)
+		h.RecordResult(start, false)
+		require.False(t, h.Available(start.Add(time.Second)), "cooldown has not elapsed yet")
+
+		probeTime := start.Add(11 * time.Second)
+		require.True(t, h.Available(probeTime), "cooldown elapsed, should allow exactly one probe")
+		assert.False(t, h.Available(probeTime), "a second concurrent request should not pile onto the same probe")
+
+		h.RecordResult(probeTime, true)
+		assert.True(t, h.Available(probeTime), "a successful probe should bring the backend back up")
+	})
+
+	t.Run("FailedProbeRestartsCooldown", func(t *testing.T) {
+		h := newBackendHealth(1, 10*time.Second)
+		start := time.Now()
+		h.RecordResult(start, false)
+
+		probeTime := start.Add(11 * time.Second)
+		require.True(t, h.Available(probeTime))
+		h.RecordResult(probeTime, false)
+
+		assert.False(t, h.Available(probeTime.Add(time.Second)), "a failed probe should restart the cooldown window")
+	})
+}
+
+func TestRoundRobinPick(t *testing.T) {
+	t.Run("CyclesThroughAllBackends", func(t *testing.T) {
+		rr := newRoundRobin(3)
+		allAvailable := func(i int) bool { return true }
+		assert.Equal(t, 0, rr.Pick(allAvailable))
+		assert.Equal(t, 1, rr.Pick(allAvailable))
+		assert.Equal(t, 2, rr.Pick(allAvailable))
+		assert.Equal(t, 0, rr.Pick(allAvailable))
+	})
+
+	t.Run("SkipsUnavailableBackends", func(t *testing.T) {
+		rr := newRoundRobin(3)
+		onlyTwo := func(i int) bool { return i == 2 }
+		assert.Equal(t, 2, rr.Pick(onlyTwo))
+		assert.Equal(t, 2, rr.Pick(onlyTwo))
+	})
+
+	t.Run("ReturnsNegativeOneWhenAllDown", func(t *testing.T) {
+		rr := newRoundRobin(3)
+		noneAvailable := func(i int) bool { return false }
+		assert.Equal(t, -1, rr.Pick(noneAvailable))
+	})
+}
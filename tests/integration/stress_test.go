@@ -1,9 +1,16 @@
 package integration
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,28 +21,1130 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// LatencySLO are the percentile thresholds StressTestConfig can assert a
+// run against. A zero value for any field disables that particular check.
+type LatencySLO struct {
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	P999 time.Duration
+}
+
 // StressTestConfig defines parameters for stress testing
 type StressTestConfig struct {
+	// NumRequests caps the total requests across all virtual users; 0
+	// means no cap, so the run lasts for the full TestDuration instead.
 	NumRequests    int
 	Concurrency    int
 	RequestTimeout time.Duration
 	TestDuration   time.Duration
 	TargetURL      string
 	MaxErrorRate   float64
+	SLO            LatencySLO
+
+	// RampUp phases the run so cold-start behavior can be told apart from
+	// steady state; its zero value hatches all Concurrency virtual users
+	// immediately, with no ramp-down.
+	RampUp RampUpConfig
+	// ThinkTime paces each virtual user between requests; its zero value
+	// applies no pause, so users loop as fast as the server responds.
+	ThinkTime ThinkTimeConfig
+}
+
+// RampUpConfig phases a StressTest run: virtual users hatch at HatchRate
+// per second until Concurrency is reached, the run then holds steady for
+// SteadyState (or until TestDuration minus RampDown, if SteadyState is
+// zero), and every request after that until TestDuration is tagged
+// PhaseRampDown. Virtual users are not stopped early during ramp-down, and
+// RampDown is only read when SteadyState is zero. This lets assertions
+// target only the steady-state window, avoiding false failures from
+// warm-up JIT/cache effects.
+type RampUpConfig struct {
+	// HatchRate is virtual users started per second during ramp-up. 0
+	// means every virtual user starts at once (no ramp-up phase).
+	HatchRate   int
+	SteadyState time.Duration
+	RampDown    time.Duration
+}
+
+// ThinkTimeDistribution selects how ThinkTimeConfig samples its pause.
+type ThinkTimeDistribution int
+
+const (
+	// ThinkTimeFixed always waits exactly Mean.
+	ThinkTimeFixed ThinkTimeDistribution = iota
+	// ThinkTimeExponential draws from an exponential distribution with
+	// mean Mean, which models human think-time better than a fixed pause:
+	// most waits are short, with an occasional long tail.
+	ThinkTimeExponential
+)
+
+// ThinkTimeConfig controls the pause a virtual user takes between
+// requests. The zero value applies no pause.
+type ThinkTimeConfig struct {
+	Distribution ThinkTimeDistribution
+	Mean         time.Duration
+}
+
+// sample draws one think-time duration using rng, which must be
+// goroutine-local: math/rand.Rand is not safe for concurrent use.
+func (c ThinkTimeConfig) sample(rng *rand.Rand) time.Duration {
+	if c.Mean <= 0 {
+		return 0
+	}
+	if c.Distribution == ThinkTimeExponential {
+		return time.Duration(rng.ExpFloat64() * float64(c.Mean))
+	}
+	return c.Mean
+}
+
+// TestPhase tags which part of a ramped StressTest run a request happened
+// in.
+type TestPhase int
+
+const (
+	PhaseRampUp TestPhase = iota
+	PhaseSteady
+	PhaseRampDown
+)
+
+func (p TestPhase) String() string {
+	switch p {
+	case PhaseRampUp:
+		return "RampUp"
+	case PhaseSteady:
+		return "Steady"
+	case PhaseRampDown:
+		return "RampDown"
+	default:
+		return "Unknown"
+	}
+}
+
+// PhaseResult aggregates one TestPhase's outcomes within a ramped
+// StressTest run.
+type PhaseResult struct {
+	TotalRequests  int64
+	SuccessfulReqs int64
+	FailedReqs     int64
+	Histogram      *Histogram
+}
+
+// ErrorRate returns the fraction of this phase's requests that failed.
+func (p *PhaseResult) ErrorRate() float64 {
+	if p.TotalRequests == 0 {
+		return 0
+	}
+	return float64(p.FailedReqs) / float64(p.TotalRequests)
+}
+
+// StressTestResult contains the results of a stress test
+type StressTestResult struct {
+	TotalRequests     int64
+	SuccessfulReqs    int64
+	FailedReqs        int64
+	TotalBytes        int64
+	Duration          time.Duration
+	RequestsPerSecond float64
+	ErrorRate         float64
+	AvgResponseTime   time.Duration
+	MinResponseTime   time.Duration
+	MaxResponseTime   time.Duration
+
+	// P50/P90/P99/P999 and Histogram replace a bare average with the tail
+	// behavior that actually determines user-perceived latency.
+	P50           time.Duration
+	P90           time.Duration
+	P99           time.Duration
+	P999          time.Duration
+	Histogram     *Histogram
+	SLOViolations []string
+
+	// HighestRPS and RPSSeries are only populated by AdaptiveStressTest: the
+	// highest actual throughput observed in an interval that stayed within
+	// both thresholds, and the full per-interval trace that led to it.
+	HighestRPS float64
+	RPSSeries  []RPSSample
+
+	// ScenarioStats is only populated by RunScenarioStressTest: per-name
+	// breakdown so a mixed workload reports per-endpoint SLOs instead of
+	// one error count blended across every request shape.
+	ScenarioStats map[string]*ScenarioResult
+
+	// IngressBytesPerSec, EgressBytesPerSec, and WorkerThroughput are only
+	// populated by RunNetworkThroughputTest: aggregate and per-worker I/O
+	// rates, which catch regressions in buffer sizing, chunked transfer,
+	// or sendfile use that a small-response RPS test can't see.
+	IngressBytesPerSec float64
+	EgressBytesPerSec  float64
+	WorkerThroughput   []WorkerThroughput
+
+	// RampUp, Steady, and RampDown break StressTest's result down by
+	// TestPhase, so assertions can target only the steady-state window
+	// instead of being skewed by ramp-up/ramp-down behavior.
+	RampUp   *PhaseResult
+	Steady   *PhaseResult
+	RampDown *PhaseResult
+}
+
+// WorkerThroughput reports one RunNetworkThroughputTest worker's totals.
+type WorkerThroughput struct {
+	WorkerID  int
+	BytesSent int64
+	BytesRecv int64
+	Requests  int64
+	Errors    int64
+}
+
+// ScenarioResult aggregates one Scenario's outcomes within a
+// RunScenarioStressTest run.
+type ScenarioResult struct {
+	Requests     int64
+	Errors       int64
+	TotalBytes   int64
+	TotalLatency time.Duration
+}
+
+// AvgLatency returns the mean request latency observed for this scenario.
+func (s *ScenarioResult) AvgLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+// ErrorRate returns the fraction of this scenario's requests that failed.
+func (s *ScenarioResult) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+const (
+	histogramMinNs = int64(time.Microsecond)
+	histogramMaxNs = int64(60 * time.Second)
+	// histogramRatio is the ratio between consecutive bucket boundaries.
+	// At 1.02 each bucket is ~2% wider than the last, which bounds the
+	// histogram's relative error to ~2-3% anywhere in its range - the same
+	// trade-off HdrHistogram makes, without the dependency.
+	histogramRatio = 1.02
+)
+
+// histogramBucketBounds is shared (read-only after init) across every
+// Histogram, since the bucket layout never varies.
+var histogramBucketBounds = computeHistogramBucketBounds()
+
+func computeHistogramBucketBounds() []int64 {
+	var bounds []int64
+	for v := float64(histogramMinNs); int64(v) < histogramMaxNs; v *= histogramRatio {
+		bounds = append(bounds, int64(v))
+	}
+	return append(bounds, histogramMaxNs)
+}
+
+// Histogram is a log-linear bucketed latency histogram spanning 1µs to 60s.
+// It is NOT safe for concurrent use: StressTest keeps one per worker
+// goroutine and merges them after the run, which is what lets per-request
+// recording avoid the contention the old atomic min/max CAS loops had.
+type Histogram struct {
+	counts []uint64
+}
+
+func newHistogram() *Histogram {
+	return &Histogram{counts: make([]uint64, len(histogramBucketBounds))}
+}
+
+// Record adds one latency observation to the histogram.
+func (h *Histogram) Record(d time.Duration) {
+	ns := d.Nanoseconds()
+	idx := sort.Search(len(histogramBucketBounds), func(i int) bool { return histogramBucketBounds[i] >= ns })
+	if idx >= len(h.counts) {
+		idx = len(h.counts) - 1
+	}
+	h.counts[idx]++
+}
+
+// merge folds other's counts into h, bucket-for-bucket.
+func (h *Histogram) merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+}
+
+// Total returns the number of observations recorded.
+func (h *Histogram) Total() uint64 {
+	var n uint64
+	for _, c := range h.counts {
+		n += c
+	}
+	return n
+}
+
+// Percentile returns the approximate latency at p (0-100], accurate to the
+// bucket width at that magnitude (~2-3%, see histogramRatio).
+func (h *Histogram) Percentile(p float64) time.Duration {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(p / 100 * float64(total)))
+	if target == 0 {
+		target = 1
+	}
+
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		if cumulative >= target {
+			return time.Duration(histogramBucketBounds[i])
+		}
+	}
+	return time.Duration(histogramMaxNs)
+}
+
+// checkLatencySLO reports, as human-readable messages, every percentile in
+// result that exceeds its (non-zero) threshold in slo.
+func checkLatencySLO(slo LatencySLO, result *StressTestResult) []string {
+	var violations []string
+	check := func(name string, threshold, actual time.Duration) {
+		if threshold > 0 && actual > threshold {
+			violations = append(violations, fmt.Sprintf("%s latency %v exceeds SLO %v", name, actual, threshold))
+		}
+	}
+	check("p50", slo.P50, result.P50)
+	check("p90", slo.P90, result.P90)
+	check("p99", slo.P99, result.P99)
+	check("p999", slo.P999, result.P999)
+	return violations
+}
+
+// AdaptiveStressConfig configures AdaptiveStressTest's AIMD ramp.
+type AdaptiveStressConfig struct {
+	TargetURL      string
+	RequestTimeout time.Duration
+	TestDuration   time.Duration
+
+	InitialRPS float64
+	MaxRPS     float64 // 0 means unbounded
+
+	// AdjustPeriod is how often the controller re-evaluates the rolling
+	// error rate and in-flight count and re-targets currentRPS.
+	AdjustPeriod time.Duration
+	// RPSStep is the additive increase applied every healthy AdjustPeriod.
+	// Defaults to 10% of InitialRPS when zero.
+	RPSStep float64
+	// BackoffFactor is the multiplicative decrease applied when either
+	// threshold is breached. Defaults to 0.7 when zero.
+	BackoffFactor float64
+
+	MaxErrorRate  float64
+	MaxOnGoingReq int
+}
+
+// RPSSample is one AdjustPeriod's throughput/error/latency/in-flight
+// snapshot, as recorded by AdaptiveStressTest's time-series.
+type RPSSample struct {
+	Time       time.Duration // offset from the start of the run
+	TargetRPS  float64
+	ActualRPS  float64
+	ErrorRate  float64
+	AvgLatency time.Duration
+	InFlight   int32
+}
+
+// tokenBucket paces dispatch at a rate that can be retargeted mid-run,
+// which is what lets AdaptiveStressTest steer RPS without tearing down and
+// recreating goroutines on every adjustment.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens/sec
+	tokens   float64
+	capacity float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, capacity: rate, last: time.Now()}
+}
+
+// setRate retargets the bucket's fill rate and burst capacity.
+func (b *tokenBucket) setRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = rate
+	b.capacity = rate
+}
+
+// allow refills tokens for the elapsed time since the last call and reports
+// whether a token was available to spend.
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	b.last = now
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// AdaptiveStressTest drives TargetURL with an AIMD-controlled request rate:
+// RPS climbs by RPSStep every AdjustPeriod as long as the rolling error
+// rate and in-flight count stay within bounds, and multiplicatively backs
+// off by BackoffFactor the moment either is breached. This finds the
+// server's sustainable throughput directly, rather than inferring it from
+// a single fixed-concurrency StressTest snapshot.
+func AdaptiveStressTest(config AdaptiveStressConfig) (*StressTestResult, error) {
+	client := &http.Client{Timeout: config.RequestTimeout}
+
+	backoffFactor := config.BackoffFactor
+	if backoffFactor <= 0 {
+		backoffFactor = 0.7
+	}
+	rpsStep := config.RPSStep
+	if rpsStep <= 0 {
+		rpsStep = config.InitialRPS * 0.1
+		if rpsStep <= 0 {
+			rpsStep = 1
+		}
+	}
+
+	currentRPS := config.InitialRPS
+	bucket := newTokenBucket(currentRPS)
+
+	var (
+		totalRequests, successfulReqs, failedReqs, totalBytes int64
+		periodRequests, periodErrors, periodRespTimeNs        int64
+		inFlight                                              int32
+		highestRPS                                            float64
+	)
+
+	mergedHistogram := newHistogram()
+	var histogramMu sync.Mutex
+	var series []RPSSample
+
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), config.TestDuration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	dispatchTicker := time.NewTicker(time.Millisecond)
+	defer dispatchTicker.Stop()
+	adjustTicker := time.NewTicker(config.AdjustPeriod)
+	defer adjustTicker.Stop()
+	periodStart := startTime
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+
+		case <-adjustTicker.C:
+			elapsed := time.Since(periodStart).Seconds()
+			reqs := atomic.SwapInt64(&periodRequests, 0)
+			errs := atomic.SwapInt64(&periodErrors, 0)
+			respTimeNs := atomic.SwapInt64(&periodRespTimeNs, 0)
+
+			actualRPS := float64(reqs) / elapsed
+			errRate := 0.0
+			if reqs > 0 {
+				errRate = float64(errs) / float64(reqs)
+			}
+			avgLatency := time.Duration(0)
+			if reqs > 0 {
+				avgLatency = time.Duration(respTimeNs / reqs)
+			}
+			currentInFlight := atomic.LoadInt32(&inFlight)
+
+			series = append(series, RPSSample{
+				Time:       time.Since(startTime),
+				TargetRPS:  currentRPS,
+				ActualRPS:  actualRPS,
+				ErrorRate:  errRate,
+				AvgLatency: avgLatency,
+				InFlight:   currentInFlight,
+			})
+
+			if errRate > config.MaxErrorRate || currentInFlight >= int32(config.MaxOnGoingReq) {
+				currentRPS *= backoffFactor
+			} else {
+				if actualRPS > highestRPS {
+					highestRPS = actualRPS
+				}
+				currentRPS += rpsStep
+				if config.MaxRPS > 0 && currentRPS > config.MaxRPS {
+					currentRPS = config.MaxRPS
+				}
+			}
+			bucket.setRate(currentRPS)
+			periodStart = time.Now()
+
+		case <-dispatchTicker.C:
+			if !bucket.allow() {
+				continue
+			}
+			if atomic.LoadInt32(&inFlight) >= int32(config.MaxOnGoingReq) {
+				continue
+			}
+
+			atomic.AddInt32(&inFlight, 1)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer atomic.AddInt32(&inFlight, -1)
+
+				localHistogram := newHistogram()
+				defer func() {
+					histogramMu.Lock()
+					mergedHistogram.merge(localHistogram)
+					histogramMu.Unlock()
+				}()
+
+				reqStart := time.Now()
+				resp, err := client.Get(config.TargetURL)
+				reqDuration := time.Since(reqStart)
+
+				atomic.AddInt64(&totalRequests, 1)
+				atomic.AddInt64(&periodRequests, 1)
+				atomic.AddInt64(&periodRespTimeNs, reqDuration.Nanoseconds())
+				localHistogram.Record(reqDuration)
+
+				if err != nil {
+					atomic.AddInt64(&failedReqs, 1)
+					atomic.AddInt64(&periodErrors, 1)
+					return
+				}
+				defer resp.Body.Close()
+
+				if resp.StatusCode >= 500 {
+					atomic.AddInt64(&failedReqs, 1)
+					atomic.AddInt64(&periodErrors, 1)
+					return
+				}
+
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					atomic.AddInt64(&failedReqs, 1)
+					atomic.AddInt64(&periodErrors, 1)
+					return
+				}
+
+				atomic.AddInt64(&successfulReqs, 1)
+				atomic.AddInt64(&totalBytes, int64(len(body)))
+			}()
+		}
+	}
+
+	wg.Wait()
+	duration := time.Since(startTime)
+
+	total := atomic.LoadInt64(&totalRequests)
+	successful := atomic.LoadInt64(&successfulReqs)
+	failed := atomic.LoadInt64(&failedReqs)
+
+	result := &StressTestResult{
+		TotalRequests:     total,
+		SuccessfulReqs:    successful,
+		FailedReqs:        failed,
+		TotalBytes:        atomic.LoadInt64(&totalBytes),
+		Duration:          duration,
+		RequestsPerSecond: float64(total) / duration.Seconds(),
+		Histogram:         mergedHistogram,
+		HighestRPS:        highestRPS,
+		RPSSeries:         series,
+	}
+	if total > 0 {
+		result.ErrorRate = float64(failed) / float64(total)
+	}
+	result.P50 = mergedHistogram.Percentile(50)
+	result.P90 = mergedHistogram.Percentile(90)
+	result.P99 = mergedHistogram.Percentile(99)
+	result.P999 = mergedHistogram.Percentile(99.9)
+	result.MinResponseTime = lowestNonEmptyBucket(mergedHistogram)
+	result.MaxResponseTime = highestNonEmptyBucket(mergedHistogram)
+
+	return result, nil
+}
+
+// Scenario is one unit of virtual-user work a WeightedScenarioSet can pick.
+// Do performs exactly one iteration against client and reports how many
+// response bytes it read, so RunScenarioStressTest can track both error
+// rate and throughput per scenario.
+type Scenario interface {
+	Name() string
+	Do(ctx context.Context, client *http.Client) (bytesRead int64, err error)
+}
+
+// doScenarioRequest runs req, drains its body, and treats a 5xx status as
+// an error - the shared tail end of every built-in Scenario.
+func doScenarioRequest(client *http.Client, req *http.Request) (int64, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return n, err
+	}
+	if resp.StatusCode >= 500 {
+		return n, fmt.Errorf("scenario request to %s got %s", req.URL, resp.Status)
+	}
+	return n, nil
+}
+
+// GetScenario issues a single GET against URL.
+type GetScenario struct {
+	URL string
+}
+
+func (g GetScenario) Name() string { return "GET " + g.URL }
+
+func (g GetScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	return doScenarioRequest(client, req)
+}
+
+// PostScenario issues a single POST of Body against URL.
+type PostScenario struct {
+	URL  string
+	Body string
+}
+
+func (p PostScenario) Name() string { return "POST " + p.URL }
+
+func (p PostScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, strings.NewReader(p.Body))
+	if err != nil {
+		return 0, err
+	}
+	return doScenarioRequest(client, req)
+}
+
+// ChunkedUploadScenario POSTs NumChunks chunks of ChunkSize bytes through
+// an io.Pipe, so the request carries no Content-Length and net/http is
+// forced to use chunked transfer encoding.
+type ChunkedUploadScenario struct {
+	URL       string
+	ChunkSize int
+	NumChunks int
+}
+
+func (c ChunkedUploadScenario) Name() string { return "chunked-upload " + c.URL }
+
+func (c ChunkedUploadScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		chunk := bytes.Repeat([]byte("x"), c.ChunkSize)
+		for i := 0; i < c.NumChunks; i++ {
+			if _, err := pw.Write(chunk); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, pr)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.ContentLength = -1
+	return doScenarioRequest(client, req)
+}
+
+// KeepAliveScenario issues Requests sequential GETs against URL through the
+// same *http.Client, so a shared transport can reuse one TCP connection
+// across all of them.
+type KeepAliveScenario struct {
+	URL      string
+	Requests int
+}
+
+func (k KeepAliveScenario) Name() string { return "keep-alive-reuse " + k.URL }
+
+func (k KeepAliveScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	var total int64
+	for i := 0; i < k.Requests; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.URL, nil)
+		if err != nil {
+			return total, err
+		}
+		n, err := doScenarioRequest(client, req)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// SlowReaderScenario GETs URL and reads the body back in small chunks with
+// a delay between each, simulating a slow client holding a connection open.
+type SlowReaderScenario struct {
+	URL       string
+	ChunkSize int
+	ReadDelay time.Duration
+}
+
+func (s SlowReaderScenario) Name() string { return "slow-reader " + s.URL }
+
+func (s SlowReaderScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var total int64
+	buf := make([]byte, s.ChunkSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		total += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(s.ReadDelay):
+		}
+	}
+	if resp.StatusCode >= 500 {
+		return total, fmt.Errorf("scenario request to %s got %s", s.URL, resp.Status)
+	}
+	return total, nil
+}
+
+// FuncScenario adapts a plain function to the Scenario interface, for
+// one-off or chained custom scenarios (e.g. a multi-step request where a
+// later step depends on an earlier response body) that don't warrant their
+// own named type.
+type FuncScenario struct {
+	ScenarioName string
+	Fn           func(ctx context.Context, client *http.Client) (bytesRead int64, err error)
+}
+
+func (f FuncScenario) Name() string { return f.ScenarioName }
+
+func (f FuncScenario) Do(ctx context.Context, client *http.Client) (int64, error) {
+	return f.Fn(ctx, client)
+}
+
+// WeightedScenarioSet samples registered Scenarios by weight, once per
+// virtual-user iteration, so a mixed workload's request mix matches a
+// target traffic shape instead of splitting evenly across scenarios.
+type WeightedScenarioSet struct {
+	mu          sync.Mutex
+	scenarios   []weightedScenario
+	totalWeight float64
+}
+
+type weightedScenario struct {
+	scenario Scenario
+	weight   float64
+}
+
+// NewWeightedScenarioSet returns an empty set ready for Register calls.
+func NewWeightedScenarioSet() *WeightedScenarioSet {
+	return &WeightedScenarioSet{}
+}
+
+// Register adds scenario to the set with the given relative weight.
+func (s *WeightedScenarioSet) Register(scenario Scenario, weight float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = append(s.scenarios, weightedScenario{scenario, weight})
+	s.totalWeight += weight
+}
+
+// Pick samples a Scenario proportionally to its registered weight. It is
+// safe for concurrent use; callers should pass a goroutine-local *rand.Rand
+// since the package-level math/rand source is itself mutex-guarded.
+func (s *WeightedScenarioSet) Pick(r *rand.Rand) Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.scenarios) == 0 {
+		return nil
+	}
+	target := r.Float64() * s.totalWeight
+	var cumulative float64
+	for _, ws := range s.scenarios {
+		cumulative += ws.weight
+		if target < cumulative {
+			return ws.scenario
+		}
+	}
+	return s.scenarios[len(s.scenarios)-1].scenario
+}
+
+// ScenarioStressConfig configures RunScenarioStressTest.
+type ScenarioStressConfig struct {
+	Scenarios      *WeightedScenarioSet
+	NumWorkers     int
+	TestDuration   time.Duration
+	RequestTimeout time.Duration
+	// ThinkTime is the pause each virtual user takes between iterations.
+	ThinkTime time.Duration
+}
+
+// RunScenarioStressTest drives NumWorkers virtual users, each repeatedly
+// picking a Scenario from config.Scenarios until TestDuration elapses. It
+// reports both an aggregate StressTestResult and, in ScenarioStats, a
+// per-scenario-name breakdown - so a mixed workload yields per-endpoint
+// SLO reports instead of one error count blended across every request
+// shape.
+func RunScenarioStressTest(config ScenarioStressConfig) (*StressTestResult, error) {
+	client := &http.Client{Timeout: config.RequestTimeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.TestDuration)
+	defer cancel()
+
+	var totalRequests, successfulReqs, failedReqs, totalBytes int64
+	mergedHistogram := newHistogram()
+	var histogramMu sync.Mutex
+
+	stats := make(map[string]*ScenarioResult)
+	var statsMu sync.Mutex
+
+	var wg sync.WaitGroup
+	startTime := time.Now()
+
+	for i := 0; i < config.NumWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := rand.New(rand.NewSource(startTime.UnixNano() + int64(workerID)))
+			localHistogram := newHistogram()
+			defer func() {
+				histogramMu.Lock()
+				mergedHistogram.merge(localHistogram)
+				histogramMu.Unlock()
+			}()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				scenario := config.Scenarios.Pick(rng)
+				if scenario == nil {
+					return
+				}
+
+				reqStart := time.Now()
+				bytesRead, err := scenario.Do(ctx, client)
+				reqDuration := time.Since(reqStart)
+
+				atomic.AddInt64(&totalRequests, 1)
+				atomic.AddInt64(&totalBytes, bytesRead)
+				localHistogram.Record(reqDuration)
+
+				statsMu.Lock()
+				sr, ok := stats[scenario.Name()]
+				if !ok {
+					sr = &ScenarioResult{}
+					stats[scenario.Name()] = sr
+				}
+				sr.Requests++
+				sr.TotalBytes += bytesRead
+				sr.TotalLatency += reqDuration
+				if err != nil {
+					sr.Errors++
+				}
+				statsMu.Unlock()
+
+				if err != nil {
+					atomic.AddInt64(&failedReqs, 1)
+				} else {
+					atomic.AddInt64(&successfulReqs, 1)
+				}
+
+				if config.ThinkTime > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(config.ThinkTime):
+					}
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	duration := time.Since(startTime)
+
+	total := atomic.LoadInt64(&totalRequests)
+	successful := atomic.LoadInt64(&successfulReqs)
+	failed := atomic.LoadInt64(&failedReqs)
+
+	result := &StressTestResult{
+		TotalRequests:     total,
+		SuccessfulReqs:    successful,
+		FailedReqs:        failed,
+		TotalBytes:        atomic.LoadInt64(&totalBytes),
+		Duration:          duration,
+		RequestsPerSecond: float64(total) / duration.Seconds(),
+		Histogram:         mergedHistogram,
+		ScenarioStats:     stats,
+	}
+	if total > 0 {
+		result.ErrorRate = float64(failed) / float64(total)
+	}
+	result.P50 = mergedHistogram.Percentile(50)
+	result.P90 = mergedHistogram.Percentile(90)
+	result.P99 = mergedHistogram.Percentile(99)
+	result.P999 = mergedHistogram.Percentile(99.9)
+	result.MinResponseTime = lowestNonEmptyBucket(mergedHistogram)
+	result.MaxResponseTime = highestNonEmptyBucket(mergedHistogram)
+
+	return result, nil
+}
+
+// NetworkThroughputConfig configures RunNetworkThroughputTest.
+type NetworkThroughputConfig struct {
+	// TargetURL must echo its request body back in the response, so both
+	// ingress and egress can be measured from the same round trip (e.g.
+	// test-server/cgi-bin/echo.py).
+	TargetURL      string
+	NumWorkers     int
+	PayloadSize    int
+	TestDuration   time.Duration
+	RequestTimeout time.Duration
+}
+
+// RunNetworkThroughputTest spins up min(NumWorkers, GOMAXPROCS) in-process
+// workers that each repeatedly POST a PayloadSize body to TargetURL and
+// read the full echoed response back, for TestDuration. Unlike StressTest,
+// which tracks request counts, this reports raw ingress/egress throughput
+// so regressions in the server's I/O path (buffer sizing, chunked
+// transfer, sendfile) show up even when request latency doesn't move.
+func RunNetworkThroughputTest(config NetworkThroughputConfig) (*StressTestResult, error) {
+	concurrency := config.NumWorkers
+	if max := runtime.GOMAXPROCS(0); concurrency > max {
+		concurrency = max
+	}
+
+	client := &http.Client{Timeout: config.RequestTimeout}
+	payload := bytes.Repeat([]byte("n"), config.PayloadSize)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.TestDuration)
+	defer cancel()
+
+	workerResults := make([]WorkerThroughput, concurrency)
+	var wg sync.WaitGroup
+	startTime := time.Now()
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			w := &workerResults[workerID]
+			w.WorkerID = workerID
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.TargetURL, bytes.NewReader(payload))
+				if err != nil {
+					w.Errors++
+					return
+				}
+				req.Header.Set("Content-Type", "application/octet-stream")
+
+				resp, err := client.Do(req)
+				if err != nil {
+					w.Errors++
+					continue
+				}
+				w.BytesSent += int64(len(payload))
+
+				n, err := io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				w.BytesRecv += n
+				w.Requests++
+				if err != nil || resp.StatusCode >= 500 {
+					w.Errors++
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	duration := time.Since(startTime)
+
+	var totalRequests, totalErrors, totalBytesSent, totalBytesRecv int64
+	for _, w := range workerResults {
+		totalRequests += w.Requests
+		totalErrors += w.Errors
+		totalBytesSent += w.BytesSent
+		totalBytesRecv += w.BytesRecv
+	}
+
+	result := &StressTestResult{
+		TotalRequests:      totalRequests,
+		SuccessfulReqs:     totalRequests - totalErrors,
+		FailedReqs:         totalErrors,
+		TotalBytes:         totalBytesRecv,
+		Duration:           duration,
+		RequestsPerSecond:  float64(totalRequests) / duration.Seconds(),
+		IngressBytesPerSec: float64(totalBytesSent) / duration.Seconds(),
+		EgressBytesPerSec:  float64(totalBytesRecv) / duration.Seconds(),
+		WorkerThroughput:   workerResults,
+	}
+	if totalRequests > 0 {
+		result.ErrorRate = float64(totalErrors) / float64(totalRequests)
+	}
+
+	return result, nil
 }
 
-// StressTestResult contains the results of a stress test
-type StressTestResult struct {
-	TotalRequests     int64
-	SuccessfulReqs    int64
-	FailedReqs        int64
-	TotalBytes        int64
-	Duration          time.Duration
-	RequestsPerSecond float64
-	ErrorRate         float64
-	AvgResponseTime   time.Duration
-	MinResponseTime   time.Duration
-	MaxResponseTime   time.Duration
+// ConnectionCapConfig configures ProbeConnectionCap.
+type ConnectionCapConfig struct {
+	Host               string
+	Port               int
+	AttemptConnections int
+	// HoldDuration is how long each worker trickles its upload body,
+	// keeping its connection genuinely open server-side for that long.
+	HoldDuration time.Duration
+	DialTimeout  time.Duration
+}
+
+// ConnectionCapResult summarizes one ProbeConnectionCap run.
+type ConnectionCapResult struct {
+	Attempted          int
+	Accepted           int
+	Refused            int
+	DroppedMidResponse int
+	MaxConcurrentOpen  int32
+}
+
+// trackedConn decrements a shared open-connection counter exactly once,
+// on whichever of the transport or the test code closes it first.
+type trackedConn struct {
+	net.Conn
+	open      *int32
+	closeOnce sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(func() { atomic.AddInt32(c.open, -1) })
+	return c.Conn.Close()
+}
+
+// ProbeConnectionCap opens AttemptConnections concurrent POST /upload
+// requests, each trickling its body over HoldDuration so the server must
+// keep the connection open for the full duration rather than completing it
+// instantly. A custom Transport.DialContext tracks exactly how many dialed
+// sockets are live at any moment, via trackedConn. This is a black-box
+// probe, not an assertion against a configured limit: this tree has no
+// exposed max_connections/worker-pool-size setting for webserv to read, so
+// the test that calls this treats "no silent drops, no unaccounted
+// attempts" as the invariant worth checking instead.
+func ProbeConnectionCap(config ConnectionCapConfig) (*ConnectionCapResult, error) {
+	var open, maxOpen int32
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{Timeout: config.DialTimeout}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			n := atomic.AddInt32(&open, 1)
+			for {
+				cur := atomic.LoadInt32(&maxOpen)
+				if n <= cur || atomic.CompareAndSwapInt32(&maxOpen, cur, n) {
+					break
+				}
+			}
+			return &trackedConn{Conn: conn, open: &open}, nil
+		},
+	}
+	client := &http.Client{Transport: transport}
+
+	result := &ConnectionCapResult{Attempted: config.AttemptConnections}
+	var resultMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < config.AttemptConnections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			pr, pw := io.Pipe()
+			go func() {
+				defer pw.Close()
+				deadline := time.Now().Add(config.HoldDuration)
+				for time.Now().Before(deadline) {
+					if _, err := pw.Write([]byte("x")); err != nil {
+						return
+					}
+					time.Sleep(50 * time.Millisecond)
+				}
+			}()
+
+			url := fmt.Sprintf("http://%s:%d/upload", config.Host, config.Port)
+			req, err := http.NewRequest(http.MethodPost, url, pr)
+			if err != nil {
+				resultMu.Lock()
+				result.Refused++
+				resultMu.Unlock()
+				return
+			}
+			req.Header.Set("Content-Type", "application/octet-stream")
+
+			resp, err := client.Do(req)
+
+			resultMu.Lock()
+			defer resultMu.Unlock()
+			if err != nil {
+				result.Refused++
+				return
+			}
+			defer resp.Body.Close()
+
+			if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+				result.DroppedMidResponse++
+				return
+			}
+			result.Accepted++
+		}()
+	}
+
+	wg.Wait()
+	result.MaxConcurrentOpen = atomic.LoadInt32(&maxOpen)
+	return result, nil
 }
 
 // StressTest performs a stress test against the webserv server
@@ -49,102 +1158,149 @@ func StressTest(config StressTestConfig) (*StressTestResult, error) {
 		successfulReqs int64
 		failedReqs     int64
 		totalBytes     int64
-		totalRespTime  int64             // in nanoseconds
-		minRespTime    int64 = 1<<63 - 1 // max int64
-		maxRespTime    int64
+		totalRespTime  int64 // in nanoseconds
 	)
 
+	// Each worker records into its own Histogram - no shared state, so no
+	// contention - and hands it to mergedHistogram once it's done.
+	mergedHistogram := newHistogram()
+	var histogramMu sync.Mutex
+
+	phaseResults := map[TestPhase]*PhaseResult{
+		PhaseRampUp:   {Histogram: newHistogram()},
+		PhaseSteady:   {Histogram: newHistogram()},
+		PhaseRampDown: {Histogram: newHistogram()},
+	}
+	var phaseMu sync.Mutex
+
 	startTime := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), config.TestDuration)
 	defer cancel()
 
-	// Channel to control concurrency
-	semaphore := make(chan struct{}, config.Concurrency)
-	var wg sync.WaitGroup
-
-	// Run requests
-	for i := 0; i < config.NumRequests; i++ {
-		select {
-		case <-ctx.Done():
-			break
+	// rampUpEnd/steadyEnd are offsets from startTime that classifyPhase
+	// compares elapsed time against. With a zero-value RampUpConfig,
+	// rampUpEnd is 0 and steadyEnd is config.TestDuration, so every
+	// request lands in PhaseSteady - preserving the un-ramped behavior
+	// this function had before RampUpConfig existed.
+	rampUpEnd := time.Duration(0)
+	if config.RampUp.HatchRate > 0 && config.Concurrency > 0 {
+		seconds := math.Ceil(float64(config.Concurrency) / float64(config.RampUp.HatchRate))
+		rampUpEnd = time.Duration(seconds * float64(time.Second))
+	}
+	steadyEnd := config.RampUp.SteadyState
+	if steadyEnd > 0 {
+		steadyEnd += rampUpEnd
+	} else {
+		steadyEnd = config.TestDuration - config.RampUp.RampDown
+		if steadyEnd < rampUpEnd {
+			steadyEnd = rampUpEnd
+		}
+	}
+	classifyPhase := func(elapsed time.Duration) TestPhase {
+		switch {
+		case elapsed < rampUpEnd:
+			return PhaseRampUp
+		case elapsed < steadyEnd:
+			return PhaseSteady
 		default:
+			return PhaseRampDown
 		}
+	}
+
+	hatchInterval := time.Duration(0)
+	if config.RampUp.HatchRate > 0 {
+		hatchInterval = time.Second / time.Duration(config.RampUp.HatchRate)
+	}
 
+	var wg sync.WaitGroup
+	for u := 0; u < config.Concurrency; u++ {
 		wg.Add(1)
-		go func(reqID int) {
+		go func(userID int, startDelay time.Duration) {
 			defer wg.Done()
 
-			semaphore <- struct{}{}        // Acquire
-			defer func() { <-semaphore }() // Release
-
-			reqStart := time.Now()
-			resp, err := client.Get(config.TargetURL)
-			reqDuration := time.Since(reqStart)
-
-			atomic.AddInt64(&totalRequests, 1)
-
-			// Update response time statistics
-			respTimeNs := reqDuration.Nanoseconds()
-			atomic.AddInt64(&totalRespTime, respTimeNs)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(startDelay):
+			}
 
-			// Update min response time
-			for {
-				currentMin := atomic.LoadInt64(&minRespTime)
-				if respTimeNs >= currentMin || atomic.CompareAndSwapInt64(&minRespTime, currentMin, respTimeNs) {
-					break
-				}
+			rng := rand.New(rand.NewSource(startTime.UnixNano() + int64(userID)))
+			localHistogram := newHistogram()
+			localPhaseHistograms := map[TestPhase]*Histogram{
+				PhaseRampUp:   newHistogram(),
+				PhaseSteady:   newHistogram(),
+				PhaseRampDown: newHistogram(),
 			}
+			defer func() {
+				histogramMu.Lock()
+				mergedHistogram.merge(localHistogram)
+				histogramMu.Unlock()
+
+				phaseMu.Lock()
+				for phase, h := range localPhaseHistograms {
+					phaseResults[phase].Histogram.merge(h)
+				}
+				phaseMu.Unlock()
+			}()
 
-			// Update max response time
 			for {
-				currentMax := atomic.LoadInt64(&maxRespTime)
-				if respTimeNs <= currentMax || atomic.CompareAndSwapInt64(&maxRespTime, currentMax, respTimeNs) {
-					break
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if config.NumRequests > 0 && atomic.LoadInt64(&totalRequests) >= int64(config.NumRequests) {
+					return
 				}
-			}
-
-			if err != nil {
-				atomic.AddInt64(&failedReqs, 1)
-				return
-			}
 
-			defer resp.Body.Close()
+				phase := classifyPhase(time.Since(startTime))
 
-			if resp.StatusCode >= 500 {
-				atomic.AddInt64(&failedReqs, 1)
-				return
-			}
+				reqStart := time.Now()
+				resp, err := client.Get(config.TargetURL)
+				reqDuration := time.Since(reqStart)
 
-			// Count bytes
-			bytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				atomic.AddInt64(&failedReqs, 1)
-				return
-			}
+				atomic.AddInt64(&totalRequests, 1)
+				atomic.AddInt64(&totalRespTime, reqDuration.Nanoseconds())
+				localHistogram.Record(reqDuration)
+				localPhaseHistograms[phase].Record(reqDuration)
 
-			atomic.AddInt64(&successfulReqs, 1)
-			atomic.AddInt64(&totalBytes, int64(len(bytes)))
-		}(i)
+				succeeded := err == nil
+				if succeeded {
+					bytesRead, readErr := io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+					succeeded = readErr == nil && resp.StatusCode < 500
+					if succeeded {
+						atomic.AddInt64(&totalBytes, bytesRead)
+					}
+				}
 
-		// Small delay to avoid overwhelming the server instantaneously
-		if i%config.Concurrency == 0 {
-			time.Sleep(1 * time.Millisecond)
-		}
-	}
+				if succeeded {
+					atomic.AddInt64(&successfulReqs, 1)
+				} else {
+					atomic.AddInt64(&failedReqs, 1)
+				}
 
-	// Wait for all requests to complete or timeout
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+				phaseMu.Lock()
+				pr := phaseResults[phase]
+				pr.TotalRequests++
+				if succeeded {
+					pr.SuccessfulReqs++
+				} else {
+					pr.FailedReqs++
+				}
+				phaseMu.Unlock()
 
-	select {
-	case <-done:
-	case <-ctx.Done():
-		// Test duration exceeded
+				think := config.ThinkTime.sample(rng)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(think):
+				}
+			}
+		}(u, time.Duration(u)*hatchInterval)
 	}
 
+	wg.Wait()
 	duration := time.Since(startTime)
 
 	// Calculate results
@@ -159,18 +1315,52 @@ func StressTest(config StressTestConfig) (*StressTestResult, error) {
 		TotalBytes:        atomic.LoadInt64(&totalBytes),
 		Duration:          duration,
 		RequestsPerSecond: float64(total) / duration.Seconds(),
-		ErrorRate:         float64(failed) / float64(total),
+		Histogram:         mergedHistogram,
+		RampUp:            phaseResults[PhaseRampUp],
+		Steady:            phaseResults[PhaseSteady],
+		RampDown:          phaseResults[PhaseRampDown],
 	}
 
 	if total > 0 {
+		result.ErrorRate = float64(failed) / float64(total)
 		result.AvgResponseTime = time.Duration(atomic.LoadInt64(&totalRespTime) / total)
 	}
-	result.MinResponseTime = time.Duration(atomic.LoadInt64(&minRespTime))
-	result.MaxResponseTime = time.Duration(atomic.LoadInt64(&maxRespTime))
+	result.P50 = mergedHistogram.Percentile(50)
+	result.P90 = mergedHistogram.Percentile(90)
+	result.P99 = mergedHistogram.Percentile(99)
+	result.P999 = mergedHistogram.Percentile(99.9)
+	// The lowest/highest non-empty bucket approximate min/max to within
+	// the histogram's own resolution, replacing the separate atomic CAS
+	// loops this function used to maintain for them.
+	result.MinResponseTime = lowestNonEmptyBucket(mergedHistogram)
+	result.MaxResponseTime = highestNonEmptyBucket(mergedHistogram)
+	result.SLOViolations = checkLatencySLO(config.SLO, result)
 
 	return result, nil
 }
 
+// lowestNonEmptyBucket returns the boundary of the first bucket in h with
+// at least one observation, or 0 if h is empty.
+func lowestNonEmptyBucket(h *Histogram) time.Duration {
+	for i, c := range h.counts {
+		if c > 0 {
+			return time.Duration(histogramBucketBounds[i])
+		}
+	}
+	return 0
+}
+
+// highestNonEmptyBucket returns the boundary of the last bucket in h with
+// at least one observation, or 0 if h is empty.
+func highestNonEmptyBucket(h *Histogram) time.Duration {
+	for i := len(h.counts) - 1; i >= 0; i-- {
+		if h.counts[i] > 0 {
+			return time.Duration(histogramBucketBounds[i])
+		}
+	}
+	return 0
+}
+
 // Test basic stress scenarios
 func TestStressBasicLoad(t *testing.T) {
 	if testing.Short() {
@@ -184,6 +1374,10 @@ func TestStressBasicLoad(t *testing.T) {
 		TestDuration:   30 * time.Second,
 		TargetURL:      getTestURL("/"),
 		MaxErrorRate:   0.05, // 5% maximum error rate
+		SLO: LatencySLO{
+			P50: 200 * time.Millisecond,
+			P99: time.Second,
+		},
 	}
 
 	result, err := StressTest(config)
@@ -198,6 +1392,7 @@ func TestStressBasicLoad(t *testing.T) {
 	t.Logf("  Avg Response Time: %v", result.AvgResponseTime)
 	t.Logf("  Min Response Time: %v", result.MinResponseTime)
 	t.Logf("  Max Response Time: %v", result.MaxResponseTime)
+	t.Logf("  P50/P90/P99/P999: %v / %v / %v / %v", result.P50, result.P90, result.P99, result.P999)
 	t.Logf("  Total Bytes: %d", result.TotalBytes)
 	t.Logf("  Duration: %v", result.Duration)
 
@@ -208,6 +1403,48 @@ func TestStressBasicLoad(t *testing.T) {
 		"Error rate should be less than %.2f%%", config.MaxErrorRate*100)
 	assert.Less(t, result.AvgResponseTime.Milliseconds(), int64(1000),
 		"Average response time should be less than 1 second")
+	assert.Empty(t, result.SLOViolations, "Latency percentiles should satisfy the configured SLO")
+}
+
+// Test a ramped run with exponential think-time between requests: the
+// steady-state window must see requests and stay under MaxErrorRate, so
+// cold-start errors during ramp-up cannot fail it. Per-phase counts are
+// logged, not asserted.
+func TestStressRampUpPhases(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
+
+	config := StressTestConfig{
+		Concurrency:    20,
+		RequestTimeout: 5 * time.Second,
+		TestDuration:   20 * time.Second,
+		TargetURL:      getTestURL("/"),
+		MaxErrorRate:   0.05,
+		RampUp: RampUpConfig{
+			HatchRate:   5, // 20 users / 5 per second = 4s ramp-up
+			SteadyState: 10 * time.Second,
+			RampDown:    5 * time.Second,
+		},
+		ThinkTime: ThinkTimeConfig{
+			Distribution: ThinkTimeExponential,
+			Mean:         20 * time.Millisecond,
+		},
+	}
+
+	result, err := StressTest(config)
+	require.NoError(t, err)
+
+	t.Logf("Ramp-Up Phases Test Results:")
+	t.Logf("  RampUp:   requests=%d errRate=%.2f%%", result.RampUp.TotalRequests, result.RampUp.ErrorRate()*100)
+	t.Logf("  Steady:   requests=%d errRate=%.2f%% p99=%v",
+		result.Steady.TotalRequests, result.Steady.ErrorRate()*100, result.Steady.Histogram.Percentile(99))
+	t.Logf("  RampDown: requests=%d errRate=%.2f%%", result.RampDown.TotalRequests, result.RampDown.ErrorRate()*100)
+
+	assert.True(t, result.TotalRequests > 0, "Should have processed some requests")
+	assert.True(t, result.Steady.TotalRequests > 0, "Steady-state phase should have processed requests")
+	assert.Less(t, result.Steady.ErrorRate(), config.MaxErrorRate,
+		"Steady-state error rate should satisfy MaxErrorRate even if ramp-up/down briefly exceeded it")
 }
 
 // Test server under heavy concurrent load
@@ -242,155 +1479,159 @@ func TestStressHighConcurrency(t *testing.T) {
 		"Error rate should be manageable even under high load")
 }
 
-// Test mixed workload (GET, POST, uploads)
+// Test mixed workload (GET, POST, uploads, keep-alive reuse, slow reads)
 func TestStressMixedWorkload(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping stress test in short mode")
 	}
 
-	client := createTestClient()
-
-	var (
-		getRequests    int64
-		postRequests   int64
-		uploadRequests int64
-		totalErrors    int64
-	)
+	scenarios := NewWeightedScenarioSet()
+	scenarios.Register(GetScenario{URL: getTestURL("/")}, 5)
+	scenarios.Register(PostScenario{URL: getTestURL("/api"), Body: "test data"}, 3)
+	scenarios.Register(ChunkedUploadScenario{URL: getTestURL("/upload"), ChunkSize: 64, NumChunks: 4}, 2)
+	scenarios.Register(KeepAliveScenario{URL: getTestURL("/"), Requests: 3}, 2)
+	scenarios.Register(SlowReaderScenario{URL: getTestURL("/"), ChunkSize: 16, ReadDelay: 5 * time.Millisecond}, 1)
 
-	const (
-		numWorkers   = 20
-		testDuration = 30 * time.Second
-	)
+	config := ScenarioStressConfig{
+		Scenarios:      scenarios,
+		NumWorkers:     20,
+		TestDuration:   30 * time.Second,
+		RequestTimeout: 5 * time.Second,
+		ThinkTime:      10 * time.Millisecond,
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), testDuration)
-	defer cancel()
+	result, err := RunScenarioStressTest(config)
+	require.NoError(t, err)
 
-	var wg sync.WaitGroup
+	t.Logf("Mixed Workload Test Results:")
+	t.Logf("  Total Requests: %d", result.TotalRequests)
+	t.Logf("  Total Errors: %d", result.FailedReqs)
+	t.Logf("  Error Rate: %.2f%%", result.ErrorRate*100)
+	for name, stats := range result.ScenarioStats {
+		t.Logf("  [%s] requests=%d errors=%d errRate=%.2f%% avgLatency=%v",
+			name, stats.Requests, stats.Errors, stats.ErrorRate()*100, stats.AvgLatency())
+	}
 
-	// Start workers for different types of requests
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(3) // GET, POST, and Upload workers
+	// Assertions
+	assert.True(t, result.TotalRequests > 0, "Should have processed requests")
+	assert.Contains(t, result.ScenarioStats, GetScenario{URL: getTestURL("/")}.Name(), "Should have processed GET requests")
+	assert.Contains(t, result.ScenarioStats, PostScenario{URL: getTestURL("/api")}.Name(), "Should have processed POST requests")
+	assert.Less(t, result.ErrorRate, 0.15, "Error rate should be reasonable under mixed load")
+}
 
-		// GET worker
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
+// Test that the AIMD controller finds a sustainable throughput rather than
+// a single fixed-concurrency snapshot.
+func TestStressAdaptiveThroughput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
 
-				resp, err := client.Get(getTestURL("/"))
-				if err != nil {
-					atomic.AddInt64(&totalErrors, 1)
-				} else {
-					resp.Body.Close()
-					if resp.StatusCode >= 400 {
-						atomic.AddInt64(&totalErrors, 1)
-					}
-				}
-				atomic.AddInt64(&getRequests, 1)
+	config := AdaptiveStressConfig{
+		TargetURL:      getTestURL("/"),
+		RequestTimeout: 5 * time.Second,
+		TestDuration:   30 * time.Second,
+		InitialRPS:     10,
+		MaxRPS:         500,
+		AdjustPeriod:   time.Second,
+		MaxErrorRate:   0.05,
+		MaxOnGoingReq:  200,
+	}
 
-				time.Sleep(10 * time.Millisecond)
-			}
-		}()
+	result, err := AdaptiveStressTest(config)
+	require.NoError(t, err)
 
-		// POST worker
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
+	t.Logf("Adaptive Stress Test Results:")
+	t.Logf("  Total Requests: %d", result.TotalRequests)
+	t.Logf("  Error Rate: %.2f%%", result.ErrorRate*100)
+	t.Logf("  Highest Sustained RPS: %.2f", result.HighestRPS)
+	t.Logf("  P50/P99: %v / %v", result.P50, result.P99)
+	for _, s := range result.RPSSeries {
+		t.Logf("  [%6v] target=%.1f actual=%.1f errRate=%.2f%% avgLatency=%v inFlight=%d",
+			s.Time.Round(time.Millisecond), s.TargetRPS, s.ActualRPS, s.ErrorRate*100, s.AvgLatency, s.InFlight)
+	}
 
-				req, err := http.NewRequest("POST", getTestURL("/api"),
-					strings.NewReader("test data"))
-				if err != nil {
-					atomic.AddInt64(&totalErrors, 1)
-					continue
-				}
+	assert.True(t, result.TotalRequests > 0, "Should have processed some requests")
+	assert.True(t, result.HighestRPS > 0, "Should have found a sustainable throughput")
+	assert.NotEmpty(t, result.RPSSeries, "Should have recorded a per-interval time-series")
+}
 
-				resp, err := client.Do(req)
-				if err != nil {
-					atomic.AddInt64(&totalErrors, 1)
-				} else {
-					resp.Body.Close()
-					if resp.StatusCode >= 500 { // Only count server errors
-						atomic.AddInt64(&totalErrors, 1)
-					}
-				}
-				atomic.AddInt64(&postRequests, 1)
+// Test aggregate ingress/egress throughput across payload sizes, not just
+// requests/sec, to catch I/O path regressions a small-response test misses.
+func TestStressNetworkThroughput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
 
-				time.Sleep(20 * time.Millisecond)
+	payloadSizes := []int{64 * 1024, 1024 * 1024, 4 * 1024 * 1024}
+
+	for _, size := range payloadSizes {
+		size := size
+		t.Run(fmt.Sprintf("%dKiB", size/1024), func(t *testing.T) {
+			config := NetworkThroughputConfig{
+				TargetURL:      getTestURL("/cgi-bin/echo.py"),
+				NumWorkers:     8,
+				PayloadSize:    size,
+				TestDuration:   10 * time.Second,
+				RequestTimeout: 10 * time.Second,
 			}
-		}()
 
-		// Upload worker
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-
-				// Small upload to avoid overwhelming
-				uploadData := "small test upload"
-				req, err := http.NewRequest("POST", getTestURL("/upload"),
-					strings.NewReader(uploadData))
-				if err != nil {
-					atomic.AddInt64(&totalErrors, 1)
-					continue
-				}
-				req.Header.Set("Content-Type", "application/octet-stream")
-
-				resp, err := client.Do(req)
-				if err != nil {
-					atomic.AddInt64(&totalErrors, 1)
-				} else {
-					resp.Body.Close()
-					if resp.StatusCode >= 500 {
-						atomic.AddInt64(&totalErrors, 1)
-					}
-				}
-				atomic.AddInt64(&uploadRequests, 1)
+			result, err := RunNetworkThroughputTest(config)
+			require.NoError(t, err)
 
-				time.Sleep(50 * time.Millisecond) // Uploads are more expensive
+			t.Logf("Network Throughput Results (%d byte payload):", size)
+			t.Logf("  Total Requests: %d", result.TotalRequests)
+			t.Logf("  Ingress: %.2f MB/s", result.IngressBytesPerSec/(1024*1024))
+			t.Logf("  Egress: %.2f MB/s", result.EgressBytesPerSec/(1024*1024))
+			for _, w := range result.WorkerThroughput {
+				t.Logf("  worker=%d sent=%d recv=%d requests=%d errors=%d",
+					w.WorkerID, w.BytesSent, w.BytesRecv, w.Requests, w.Errors)
 			}
-		}()
-	}
 
-	// Wait for test to complete
-	<-ctx.Done()
-
-	// Give workers a moment to finish current requests
-	time.Sleep(100 * time.Millisecond)
+			assert.True(t, result.TotalRequests > 0, "Should have processed some requests")
+			assert.True(t, result.IngressBytesPerSec > 0, "Should report positive ingress throughput")
+			assert.True(t, result.EgressBytesPerSec > 0, "Should report positive egress throughput")
+			assert.Less(t, result.ErrorRate, 0.05, "Error rate should be low for a plain echo round trip")
+		})
+	}
+}
 
-	gets := atomic.LoadInt64(&getRequests)
-	posts := atomic.LoadInt64(&postRequests)
-	uploads := atomic.LoadInt64(&uploadRequests)
-	errors := atomic.LoadInt64(&totalErrors)
-	total := gets + posts + uploads
+// Test that the server handles more concurrent long-lived connections than
+// any reasonable worker pool accepts instantly, with clean backpressure:
+// no connection silently dropped mid-response, and every attempt accounted
+// for as either accepted or cleanly refused.
+func TestStressMaxOpenConnections(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
 
-	t.Logf("Mixed Workload Test Results:")
-	t.Logf("  GET Requests: %d", gets)
-	t.Logf("  POST Requests: %d", posts)
-	t.Logf("  Upload Requests: %d", uploads)
-	t.Logf("  Total Requests: %d", total)
-	t.Logf("  Total Errors: %d", errors)
-	t.Logf("  Error Rate: %.2f%%", float64(errors)/float64(total)*100)
+	// This tree has no exposed max_connections/worker-pool-size config for
+	// webserv, so there is no known cap to assert the observed max against
+	// directly; see ProbeConnectionCap's doc comment for what this checks
+	// instead.
+	const attemptConnections = 200
+
+	config := ConnectionCapConfig{
+		Host:               testServerHost,
+		Port:               testServerPort,
+		AttemptConnections: attemptConnections,
+		HoldDuration:       2 * time.Second,
+		DialTimeout:        5 * time.Second,
+	}
 
-	// Assertions
-	assert.True(t, total > 0, "Should have processed requests")
-	assert.True(t, gets > 0, "Should have processed GET requests")
-	assert.True(t, posts > 0, "Should have processed POST requests")
+	result, err := ProbeConnectionCap(config)
+	require.NoError(t, err)
 
-	errorRate := float64(errors) / float64(total)
-	assert.Less(t, errorRate, 0.15, "Error rate should be reasonable under mixed load")
+	t.Logf("Connection Cap Probe Results:")
+	t.Logf("  Attempted: %d", result.Attempted)
+	t.Logf("  Accepted: %d", result.Accepted)
+	t.Logf("  Refused: %d", result.Refused)
+	t.Logf("  Dropped mid-response: %d", result.DroppedMidResponse)
+	t.Logf("  Max concurrent open sockets: %d", result.MaxConcurrentOpen)
+
+	assert.Zero(t, result.DroppedMidResponse, "No connection should be silently dropped mid-response")
+	assert.Equal(t, result.Attempted, result.Accepted+result.Refused,
+		"Every attempted connection should end up either accepted or cleanly refused")
+	assert.True(t, result.MaxConcurrentOpen > 0, "Should have observed at least one concurrently open connection")
 }
 
 // Test server memory stability under prolonged load
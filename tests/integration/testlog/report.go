@@ -0,0 +1,125 @@
+package testlog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaseResult is one test's outcome, ready to be rendered as a JUnit <testcase>
+// or a TAP line.
+type CaseResult struct {
+	Name     string
+	Duration time.Duration
+	Failed   bool
+	Skipped  bool
+	Message  string
+}
+
+// Report accumulates CaseResults across the suite and renders them in both
+// JUnit XML (for dashboards that understand Surefire-style reports) and TAP
+// (for anything that only speaks the Test Anything Protocol).
+type Report struct {
+	mu    sync.Mutex
+	suite string
+	cases []CaseResult
+}
+
+// NewReport creates a report for a suite named suite (e.g. the package name).
+func NewReport(suite string) *Report {
+	return &Report{suite: suite}
+}
+
+// Add records one test's outcome. Safe to call from parallel subtests.
+func (r *Report) Add(c CaseResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cases = append(r.cases, c)
+}
+
+type junitTestsuite struct {
+	XMLName   xml.Name       `xml:"testsuite"`
+	Name      string         `xml:"name,attr"`
+	Tests     int            `xml:"tests,attr"`
+	Failures  int            `xml:"failures,attr"`
+	Skipped   int            `xml:"skipped,attr"`
+	TestCases []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+	Skipped *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit renders the report as JUnit XML to path.
+func (r *Report) WriteJUnit(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suite := junitTestsuite{
+		Name:  r.suite,
+		Tests: len(r.cases),
+	}
+
+	for _, c := range r.cases {
+		tc := junitTestcase{
+			Name: c.Name,
+			Time: c.Duration.Seconds(),
+		}
+		switch {
+		case c.Failed:
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: c.Message}
+		case c.Skipped:
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{Message: c.Message}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// WriteTAP renders the report in TAP (Test Anything Protocol) format to path.
+func (r *Report) WriteTAP(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "TAP version 13\n1..%d\n", len(r.cases))
+
+	for i, c := range r.cases {
+		status := "ok"
+		if c.Failed {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s", status, i+1, c.Name)
+		if c.Skipped {
+			fmt.Fprintf(&b, " # SKIP %s", c.Message)
+		} else if c.Failed && c.Message != "" {
+			fmt.Fprintf(&b, " # %s", c.Message)
+		}
+		b.WriteByte('\n')
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
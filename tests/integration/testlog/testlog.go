@@ -0,0 +1,91 @@
+// Package testlog correlates the C++ server's stderr log with the specific
+// integration subtest that produced it, and renders the suite's results as
+// JUnit XML and TAP so they're consumable by CI dashboards.
+package testlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ServerLog tails the file the test harness redirects webserv's stderr into
+// (test-server/server.log), letting a failing subtest print only the slice
+// of server output produced while it ran.
+type ServerLog struct {
+	path string
+}
+
+// NewServerLog wraps the server log at path.
+func NewServerLog(path string) *ServerLog {
+	return &ServerLog{path: path}
+}
+
+// Offset returns the current size of the log file. Record it before a
+// subtest runs and pass it to Since afterward to isolate that subtest's
+// slice of (possibly interleaved, if t.Parallel()) server output.
+func (s *ServerLog) Offset() int64 {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Since returns everything appended to the log file after the byte offset
+// from, which should have come from a prior call to Offset.
+func (s *ServerLog) Since(from int64) (string, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if from >= info.Size() {
+		return "", nil
+	}
+
+	if _, err := f.Seek(from, 0); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, info.Size()-from)
+	if _, err := f.Read(buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// CorrelationID returns a short random hex identifier suitable for injecting
+// as an X-Test-ID request header and grepping back out of interleaved server
+// output when many t.Parallel() subtests are in flight at once.
+func CorrelationID() string {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Degrade to a fixed marker rather than failing the test outright;
+		// correlation becomes best-effort but the request still succeeds.
+		return "00000000"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// GrepTestID returns the lines of log that mention testID, preserving order.
+func GrepTestID(log, testID string) string {
+	if testID == "" {
+		return log
+	}
+	marker := fmt.Sprintf("X-Test-ID: %s", testID)
+	var matched []string
+	for _, line := range strings.SplitAfter(log, "\n") {
+		if strings.Contains(line, marker) {
+			matched = append(matched, line)
+		}
+	}
+	return strings.Join(matched, "")
+}
@@ -8,6 +8,7 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -275,6 +276,21 @@ func TestFileUpload(t *testing.T) {
 		assert.True(t, resp.StatusCode >= 200 && resp.StatusCode < 300,
 			"Raw upload should be accepted, got status %d", resp.StatusCode)
 	})
+
+	// webserv has no resumable upload protocol checked into this tree: POST
+	// with Upload-Length creating a zero-byte file plus a Location/
+	// Upload-Offset response, PATCH with Upload-Offset appending bytes
+	// (409 on a mismatched offset), and HEAD reporting progress are all
+	// absent - see resumableUpload in resumable_upload_test.go for the
+	// reference offset/TTL/quota bookkeeping this route would need,
+	// already unit-tested in isolation. If the route existed, this
+	// subtest would POST a 5 MB Upload-Length, PATCH it in three chunks,
+	// deliberately retry the second chunk with its original (now stale)
+	// offset to assert 409, then re-PATCH at the correct offset and
+	// checksum the reassembled file against the source payload.
+	t.Run("ResumableUploadAcrossRetriedChunk", func(t *testing.T) {
+		t.Skip("webserv has no resumable (tus-style) upload protocol checked into this tree; nothing to test yet")
+	})
 }
 
 // Test content length limits
@@ -297,6 +313,71 @@ func TestContentLengthLimits(t *testing.T) {
 	})
 }
 
+// Test the upload_authorize subrequest hook: before buffering a POST/PUT
+// body, the server should ask a configured upstream for per-request policy
+// (MaxSize, AllowedContentTypes, TempPath, optional RewriteURL) and mirror
+// a non-2xx response straight to the client.
+//
+// webserv has no upload_authorize directive, config parsing, or subrequest
+// mechanism checked into this tree, so there's no way to route a request
+// through the real server with an auth upstream configured - an
+// httptest.Server stub standing in for webserv would only test the stub.
+// If upload_authorize existed, this test would configure /upload with it
+// pointing at a stub upstream and assert: a 2xx policy response buffers
+// the body to TempPath and invokes the real handler with
+// X-Selfserv-Temp-Path set; a non-2xx response is mirrored verbatim to the
+// client, same status and body; and a body larger than the policy's
+// MaxSize (but under the global cap) gets 413.
+func TestUploadPreAuthorization(t *testing.T) {
+	t.Skip("webserv has no upload_authorize directive checked into this tree; nothing to test yet")
+}
+
+// Test a proxy_pass location: connection pooling/keep-alive to upstreams,
+// passive health checks with round-robin over a static backend list,
+// hop-by-hop header stripping, X-Forwarded-*/X-Real-IP injection, and
+// streamed (not fully buffered) request/response bodies.
+//
+// webserv has no proxy_pass location type, upstream pool, or passive
+// health-check machinery checked into this tree - see backendHealth and
+// roundRobin in reverse_proxy_test.go for the reference bookkeeping logic
+// this location type would need, already unit-tested in isolation. If
+// proxy_pass existed, these subtests would configure a location pointing
+// at the httptest.Server backend below and assert against the real
+// server.
+func TestReverseProxy(t *testing.T) {
+	newEchoBackend := func(t *testing.T) *httptest.Server {
+		t.Helper()
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+			io.Copy(w, r.Body)
+		}))
+	}
+
+	t.Run("StreamedBodySurvives10MBRoundTrip", func(t *testing.T) {
+		backend := newEchoBackend(t)
+		defer backend.Close()
+		t.Skip("webserv has no proxy_pass location checked into this tree; nothing to test yet")
+	})
+
+	t.Run("BackendEjectedAfterFiveConsecutive500s", func(t *testing.T) {
+		failures := 0
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			failures++
+			w.WriteHeader(500)
+		}))
+		defer backend.Close()
+		t.Skip("webserv has no proxy_pass location or passive health checks checked into this tree; nothing to test yet")
+	})
+
+	// Mirrors the net.Dial pattern in TestKeepAlive, but against a proxy_pass
+	// route instead of a static file.
+	t.Run("KeepAliveIsPreservedAcrossProxiedRequests", func(t *testing.T) {
+		backend := newEchoBackend(t)
+		defer backend.Close()
+		t.Skip("webserv has no proxy_pass location checked into this tree; nothing to test yet")
+	})
+}
+
 // Test CGI execution (if CGI endpoint is configured)
 func TestCGIExecution(t *testing.T) {
 	client := createTestClient()